@@ -0,0 +1,34 @@
+package gold
+
+import (
+	"io"
+)
+
+// A Backend represents the template package which executes the source codes
+// generated from Gold templates.
+type Backend int
+
+// Backends
+const (
+	// HTMLBackend generates html/template package's templates which escape
+	// the outputs contextually.
+	HTMLBackend Backend = iota
+	// TextBackend generates text/template package's templates which write
+	// the outputs as they are.
+	TextBackend
+	// XMLBackend generates text/template package's templates which generate
+	// XML documents.
+	XMLBackend
+	// PlainTextBackend generates text/template package's templates which
+	// write the texts of the HTML elements as a readable plain text. Links
+	// are written as footnotes and list items as bullets.
+	PlainTextBackend
+)
+
+// An Executor represents a template which both of the html/template and
+// text/template packages' templates implement.
+type Executor interface {
+	Execute(wr io.Writer, data interface{}) error
+	ExecuteTemplate(wr io.Writer, name string, data interface{}) error
+	Name() string
+}
@@ -9,16 +9,32 @@ type Block struct {
 	Name     string
 	Elements []*Element
 	Template *Template
+	LineNo   int
 }
 
 // AppendChild appends the element to the receiver block.
 func (b *Block) AppendChild(child *Element) {
-	b.Elements = append(b.Elements, child)
+	b.Elements = appendElement(b.Elements, child)
 }
 
-// Html writes the block's html to the buffer.
+// Html writes the block's html to the buffer. Use WriteHtml to get the
+// error which occurs while writing.
 func (b *Block) Html(bf *bytes.Buffer, stringTemplates map[string]string) {
+	b.WriteHtml(bf, stringTemplates)
+}
+
+// WriteHtml writes the block's html to the buffer and returns the error
+// which occurs while writing.
+func (b *Block) WriteHtml(bf *bytes.Buffer, stringTemplates map[string]string) error {
+	return b.html(bf, newContext(stringTemplates))
+}
+
+// html writes the block's html to the buffer within the context.
+func (b *Block) html(bf *bytes.Buffer, ctx *context) error {
 	for _, e := range b.Elements {
-		e.Html(bf, stringTemplates)
+		if err := e.html(bf, ctx); err != nil {
+			return err
+		}
 	}
+	return nil
 }
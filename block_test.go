@@ -30,3 +30,32 @@ func TestBlockHtml(t *testing.T) {
 		t.Errorf("Html returns an invalid string.")
 	}
 }
+
+func TestBlockWriteHtml(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	b := &Block{Name: "content", Template: tpl}
+	e, err := NewElement("p Hello", 1, 0, nil, tpl, b)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	b.AppendChild(e)
+	var bf bytes.Buffer
+	if err := b.WriteHtml(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if bf.String() != "<p>Hello</p>" {
+		t.Errorf("WriteHtml writes an invalid string.")
+	}
+
+	// When the super templates do not define the block.
+	e, err = NewElement("super", 2, 0, nil, tpl, b)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	b.AppendChild(e)
+	expectedErrMsg := "The block content is not defined in the super templates. (line no: 2)"
+	if err := b.WriteHtml(&bf, nil); err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
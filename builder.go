@@ -0,0 +1,177 @@
+package gold
+
+import (
+	"strings"
+)
+
+// NewTag generates a new tag element and returns it. The contents of script
+// and style elements are raw contents.
+func NewTag(tag string) *Element {
+	return &Element{Text: tag, Tokens: []string{tag}, Tag: tag, Type: TypeTag, Attributes: make(map[string]string), RawContent: tag == "script" || tag == "style"}
+}
+
+// NewText generates a new text element ("| text") and returns it.
+func NewText(text string) *Element {
+	e := newLineElement(TypeLiteral, "|", text)
+	e.Tokens = []string{"|", text}
+	return e
+}
+
+// NewOutputExpression generates a new output expression element
+// ("= pipeline") and returns it.
+func NewOutputExpression(pipeline string) *Element {
+	return newLineElement(TypeOutputExpression, "=", pipeline)
+}
+
+// NewComment generates a new comment element ("// text") and returns it.
+func NewComment(text string) *Element {
+	return newLineElement(TypeTag, "//", text)
+}
+
+// NewContent generates a new content element which is a line of a raw
+// content and returns it.
+func NewContent(text string) *Element {
+	return &Element{Text: text, Tokens: words(text, defaultDelimLeft, defaultDelimRight), Type: TypeContent, Attributes: make(map[string]string)}
+}
+
+// NewInclude generates a new include element ("include path params") and
+// returns it. A parameter has the form of "name=value".
+func NewInclude(path string, params ...string) *Element {
+	return newLineElement(TypeInclude, "include", strings.Join(append([]string{path}, params...), " "))
+}
+
+// NewBlockElement generates a new block element ("block name") and returns
+// it. The element is replaced with the sub template's block which has the
+// name. The element's children are rendered if the sub template does not
+// have the block.
+func NewBlockElement(name string) *Element {
+	return newLineElement(TypeBlock, "block", name)
+}
+
+// NewBlock generates a new block which overrides the super template's block
+// element and returns it.
+func NewBlock(name string) *Block {
+	return &Block{Name: name}
+}
+
+// newLineElement generates a new element whose line consists of the keyword
+// and the string and returns it.
+func newLineElement(typ string, keyword string, s string) *Element {
+	text := keyword
+	if s != "" {
+		text += " " + s
+	}
+	return &Element{Text: text, Tokens: words(text, defaultDelimLeft, defaultDelimRight), Type: typ, Attributes: make(map[string]string)}
+}
+
+// WithId sets the id to the element and returns the element.
+func (e *Element) WithId(id string) *Element {
+	e.Id = id
+	return e
+}
+
+// WithClasses appends the classes to the element's classes and returns the
+// element.
+func (e *Element) WithClasses(classes ...string) *Element {
+	for _, class := range classes {
+		e.appendClass(class)
+	}
+	return e
+}
+
+// WithAttribute sets the attribute to the element and returns the element.
+// An id attribute replaces the element's id.
+func (e *Element) WithAttribute(k, v string) *Element {
+	if k == "id" {
+		return e.WithId(v)
+	}
+	e.SetAttribute(k, v)
+	return e
+}
+
+// WithBooleanAttributes appends the boolean attributes ([name]) to the
+// element and returns the element.
+func (e *Element) WithBooleanAttributes(names ...string) *Element {
+	e.SingleAttributes = append(e.SingleAttributes, names...)
+	return e
+}
+
+// WithText appends the text to the element's text values and returns the
+// element.
+func (e *Element) WithText(text string) *Element {
+	e.appendTextValue(text)
+	return e
+}
+
+// WithRawContent makes the element's children the lines of a raw content
+// and returns the element.
+func (e *Element) WithRawContent() *Element {
+	e.RawContent = true
+	return e
+}
+
+// WithChildren appends the children to the element and returns the element.
+func (e *Element) WithChildren(children ...*Element) *Element {
+	for _, child := range children {
+		child.Parent = e
+		child.setIndent(e.Indent + 1)
+		e.AppendChild(child)
+	}
+	return e
+}
+
+// setIndent sets the indent to the element and its descendants.
+func (e *Element) setIndent(indent int) {
+	e.Indent = indent
+	for _, child := range e.Children {
+		if child.Inline || child == e.Nested {
+			child.setIndent(indent)
+		} else {
+			child.setIndent(indent + 1)
+		}
+	}
+}
+
+// WithElements appends the elements to the block and returns the block.
+func (b *Block) WithElements(elements ...*Element) *Block {
+	for _, e := range elements {
+		e.Block = b
+		e.setIndent(indentTop + 1)
+		b.AppendChild(e)
+	}
+	return b
+}
+
+// WithExtends sets the path of the super template to the template and
+// returns the template.
+func (t *Template) WithExtends(path string) *Template {
+	t.Extends = path
+	return t
+}
+
+// WithParams appends the parameters to the template's parameters and
+// returns the template.
+func (t *Template) WithParams(params ...*Param) *Template {
+	t.Params = append(t.Params, params...)
+	return t
+}
+
+// WithElements appends the elements to the template's top elements and
+// returns the template.
+func (t *Template) WithElements(elements ...*Element) *Template {
+	for _, e := range elements {
+		e.Template = t
+		e.setIndent(indentTop)
+		t.AppendElement(e)
+	}
+	return t
+}
+
+// WithBlocks adds the blocks to the template and returns the template.
+func (t *Template) WithBlocks(blocks ...*Block) *Template {
+	for _, b := range blocks {
+		b.Template = t
+		t.AddBlock(b.Name, b)
+	}
+	return t
+}
@@ -0,0 +1,88 @@
+package gold
+
+import (
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("index", g).WithElements(
+		NewTag("doctype").WithText("html"),
+		NewTag("html").WithChildren(
+			NewTag("body").WithId("main").WithClasses("container", "wide").WithAttribute("data-title", "Gold page").WithChildren(
+				NewComment("navigation"),
+				NewTag("a").WithAttribute("href", "/about").WithAttribute("class", "link").WithText("About"),
+				NewTag("input").WithAttribute("type", "checkbox").WithBooleanAttributes("checked"),
+				NewTag("p").WithText("a=b"),
+				NewText("Hello"),
+				NewOutputExpression(".Name"),
+				NewInclude("footer", "year=2014"),
+				NewTag("pre").WithClasses("code").WithRawContent().WithChildren(
+					NewContent("var a = 1;"),
+				),
+			),
+		),
+	)
+	expectedString := `doctype html
+html
+  body#main.container.wide data-title="Gold page"
+    // navigation
+    a.link href=/about About
+    input type=checkbox [checked]
+    p | a=b
+    | Hello
+    = .Name
+    include footer year=2014
+    pre.code.
+      var a = 1;
+`
+	src := Sprint(tpl)
+	if src != expectedString {
+		t.Errorf("Source should be %s. [actual: %s]", expectedString, src)
+	}
+	stringTemplates := map[string]string{"index": src, "footer": "footer %{year}"}
+	_, html, err := g.ParseStringWithHTML(stringTemplates, "index")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	builtHtml, err := tpl.Html(stringTemplates, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if builtHtml != html {
+		t.Errorf("HTML should be %s. [actual: %s]", html, builtHtml)
+	}
+
+	// When the template extends a super template.
+	tpl = NewTemplate("page", g).WithExtends("base").WithBlocks(
+		NewBlock("title").WithElements(NewText("Title")),
+		NewBlock("content").WithElements(NewTag("p").WithText("Content")),
+	)
+	expectedString = `extends base
+block content
+  p Content
+block title
+  | Title
+`
+	if src := Sprint(tpl); src != expectedString {
+		t.Errorf("Source should be %s. [actual: %s]", expectedString, src)
+	}
+	_, html, err = g.ParseStringWithHTML(map[string]string{"page": Sprint(tpl), "base": "html\n  head\n    title\n      block title\n  body\n    block content"}, "page")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = "<html><head><title>Title</title></head><body><p>Content</p></body></html>"
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When a block element has children.
+	e := NewBlockElement("content").WithChildren(NewTag("p").WithText("Default"))
+	expectedString = "block content\n  p Default\n"
+	if src := Sprint(e); src != expectedString {
+		t.Errorf("Source should be %s. [actual: %s]", expectedString, src)
+	}
+	if e.Children[0].Indent != 1 || e.Children[0].Parent != e {
+		t.Errorf("The child should be indented and linked to the parent.")
+	}
+}
@@ -0,0 +1,91 @@
+// Command gold is a tool for Gold templates.
+//
+// Usage:
+//
+//	gold ast [-basedir dir] path
+//
+// The ast command parses the Gold template and writes its parse tree to the
+// standard output as JSON. If the template has errors, the partial tree is
+// written and all the errors are written to the standard error.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yosssi/gold"
+)
+
+// usage is the usage of the command.
+const usage = `usage: gold ast [-basedir dir] path
+
+Commands:
+  ast  writes the parse tree of the Gold template as JSON
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command with the arguments and writes its output to the
+// writer.
+func run(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "ast":
+		return ast(args[1:], w)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+// ast parses the template and writes its parse tree as JSON to the writer.
+func ast(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("ast", flag.ContinueOnError)
+	baseDir := fs.String("basedir", "", "base directory of the templates")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New(usage)
+	}
+	g := gold.NewGenerator(false)
+	if *baseDir != "" {
+		g.SetBaseDir(*baseDir)
+	}
+	tpl, parseErr := g.ParseTree(fs.Arg(0))
+	if tpl == nil {
+		return parseErr
+	}
+	b, err := json.MarshalIndent(tpl, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if errs, ok := parseErr.(gold.ErrorList); ok {
+		return errorList(errs)
+	}
+	return parseErr
+}
+
+// errorList returns an error whose message has the messages of all the
+// errors.
+func errorList(errs gold.ErrorList) error {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var bf bytes.Buffer
+	if err := run([]string{"ast", "-basedir", "../../test/TestGeneratorParseFile", "012.gold"}, &bf); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var tree struct {
+		Kind    string `json:"kind"`
+		Path    string `json:"path"`
+		Extends string `json:"extends"`
+	}
+	if err := json.Unmarshal(bf.Bytes(), &tree); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if tree.Kind != "template" || tree.Path != "../../test/TestGeneratorParseFile/012.gold" || tree.Extends != "../../test/TestGeneratorParseFile/./012_super.gold" {
+		t.Errorf("The tree is invalid. [actual: %+v]", tree)
+	}
+
+	// When no command is given.
+	if err := run(nil, &bf); err == nil || err.Error() != usage {
+		t.Errorf("The usage should be returned.")
+	}
+
+	// When an unknown command is given.
+	err := run([]string{"fmt"}, &bf)
+	expectedErrMsg := "unknown command \"fmt\"\n" + usage
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the template has errors.
+	bf.Reset()
+	err = run([]string{"ast", "../../test/TestGeneratorParseFile/011.gold"}, &bf)
+	expectedErrMsg = "The number of the element id has to be one. (line no: 4)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+	if !json.Valid(bf.Bytes()) {
+		t.Errorf("The partial tree should be written. [actual: %s]", bf.String())
+	}
+
+	// When the template does not exist.
+	if err := run([]string{"ast", "not_exist.gold"}, &bf); err == nil {
+		t.Errorf("An error should be returned.")
+	}
+}
@@ -0,0 +1,84 @@
+package gold
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// A context holds the states which are shared while a template's HTML is
+// generated.
+type context struct {
+	stringTemplates map[string]string
+	// xhtml is true if the template's doctype is based on XML. Void elements
+	// are closed and boolean attributes are written with values.
+	xhtml bool
+	// xml is true if the template generates an XML document. Empty elements
+	// are closed and the outputs of actions are escaped for XML.
+	xml bool
+	// text is true if the template is executed by the text/template package.
+	// Literal lines end with line feeds.
+	text bool
+	// plain is true if the template writes the texts of the HTML elements
+	// instead of the elements.
+	plain bool
+	// links holds the link targets which are written as footnotes.
+	links []string
+	// subs holds the templates which extend the templates being rendered.
+	// The parsed templates are not modified so that a super template can be
+	// shared by the templates which extend it.
+	subs []*Template
+	// scopes is the number of the templates being rendered which embed
+	// parameters. An included template's HTML is written as a marker while
+	// a template embeds parameters so that the parameters are not embedded
+	// to it.
+	scopes int
+	// includes holds the included templates' HTML which are written as
+	// markers.
+	includes []string
+}
+
+// newContext generates a new context and returns it.
+func newContext(stringTemplates map[string]string) *context {
+	return &context{stringTemplates: stringTemplates}
+}
+
+// sub returns the template which extends the template within the context
+// or nil.
+func (ctx *context) sub(tpl *Template) *Template {
+	for i := len(ctx.subs) - 1; i >= 0; i-- {
+		if ctx.subs[i].Super == tpl {
+			return ctx.subs[i]
+		}
+	}
+	return nil
+}
+
+// block returns the block which overrides the template's block element
+// which has the name. The block of the most derived template is returned
+// so that a template can override the block elements of all its ancestors.
+func (ctx *context) block(tpl *Template, name string) *Block {
+	var block *Block
+	for sub := ctx.sub(tpl); sub != nil; sub = ctx.sub(sub) {
+		if b, prs := sub.Blocks[name]; prs {
+			block = b
+		}
+	}
+	return block
+}
+
+// writeInclude writes the included template's HTML to the buffer. The HTML
+// is written as a marker if a template embeds parameters.
+func (ctx *context) writeInclude(bf *bytes.Buffer, html string) {
+	if ctx.scopes == 0 {
+		bf.WriteString(html)
+		return
+	}
+	bf.WriteString(includeMarker(len(ctx.includes)))
+	ctx.includes = append(ctx.includes, html)
+}
+
+// includeMarker returns the marker of the included template's HTML which
+// has the index.
+func includeMarker(i int) string {
+	return "\x00include:" + strconv.Itoa(i) + "\x00"
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -15,6 +17,18 @@ const (
 	TypeLiteral           = "literal"
 	TypeInclude           = "include"
 	TypeOutputExpression  = "outputExpression"
+	TypeDefine            = "define"
+	TypeTemplate          = "template"
+	TypeIf                = "if"
+	TypeElse              = "else"
+	TypeRange             = "range"
+	TypeWith              = "with"
+	TypeEach              = "each"
+	TypeCase              = "case"
+	TypeWhen              = "when"
+	TypeDefault           = "default"
+	TypeFilter            = "filter"
+	TypeSuper             = "super"
 	IncludeParaStartIndex = 2
 )
 
@@ -28,6 +42,14 @@ var (
 		"1.1":          "<!DOCTYPE html PUBLIC \"-//W3C//DTD XHTML 1.1//EN\" \"http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd\">",
 		"basic":        "<!DOCTYPE html PUBLIC \"-//W3C//DTD XHTML Basic 1.1//EN\" \"http://www.w3.org/TR/xhtml-basic/xhtml-basic11.dtd\">",
 		"mobile":       "<!DOCTYPE html PUBLIC \"-//WAPFORUM//DTD XHTML Mobile 1.2//EN\" \"http://www.openmobilealliance.org/tech/DTD/xhtml-mobile12.dtd\">",
+		"5":            "<!DOCTYPE html>",
+		"html5":        "<!DOCTYPE html>",
+		"svg":          "<!DOCTYPE svg PUBLIC \"-//W3C//DTD SVG 1.1//EN\" \"http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd\">",
+		"plist":        "<!DOCTYPE plist PUBLIC \"-//Apple//DTD PLIST 1.0//EN\" \"http://www.apple.com/DTDs/PropertyList-1.0.dtd\">",
+	}
+	voidElements = map[string]bool{
+		"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+		"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
 	}
 )
 
@@ -49,6 +71,17 @@ type Element struct {
 	Template         *Template
 	Block            *Block
 	RawContent       bool
+	Else             *Element
+	Head             *Element
+	Nested           *Element
+	Inline           bool
+	SpaceBefore      bool
+	SpaceAfter       bool
+	Filtered         string
+	Unescaped        bool
+	Col              int
+	Lexemes          []Token
+	attrOrder        []string
 }
 
 // parse parses the element.
@@ -57,25 +90,138 @@ func (e *Element) parse() error {
 		return errors.New(fmt.Sprintf("The element has no tokens. (line no: %d)", e.LineNo))
 	}
 	switch {
-	case e.Type == TypeContent || e.Type == TypeBlock || e.Type == TypeExpression || e.Type == TypeLiteral || e.Type == TypeInclude || e.Type == TypeOutputExpression || e.comment():
+	case e.Type == TypeDefine:
+		if len(e.Tokens) != 2 {
+			return errors.New(fmt.Sprintf("The define element has to have one name. (line no: %d)", e.LineNo))
+		}
+		if e.Parent != nil || e.Block != nil {
+			return errors.New(fmt.Sprintf("The define element has to be a top element. (line no: %d)", e.LineNo))
+		}
+	case e.Type == TypeIf || e.Type == TypeRange || e.Type == TypeWith:
+		if len(e.Tokens) < 2 {
+			return errors.New(fmt.Sprintf("The %s element does not have a pipeline. (line no: %d)", e.Type, e.LineNo))
+		}
+	case e.Type == TypeElse:
+		if len(e.Tokens) > 1 && (e.Tokens[1] != "if" || len(e.Tokens) < 3) {
+			return errors.New(fmt.Sprintf("The else element has to be \"else\" or \"else if pipeline\". (line no: %d)", e.LineNo))
+		}
+	case e.Type == TypeCase:
+		if len(e.Tokens) < 2 {
+			return errors.New(fmt.Sprintf("The case element does not have a pipeline. (line no: %d)", e.LineNo))
+		}
+	case e.Type == TypeWhen || e.Type == TypeDefault:
+		if e.Parent == nil || e.Parent.Type != TypeCase {
+			return errors.New(fmt.Sprintf("The %s element has to be a child of a case element. (line no: %d)", e.Type, e.LineNo))
+		}
+		if e.Type == TypeWhen {
+			values, err := e.whenValues()
+			if err != nil {
+				return err
+			}
+			e.TextValues = values
+		}
+	case e.Type == TypeFilter:
+		if e.getGenerator().filter(e.filterName()) == nil {
+			return errors.New(fmt.Sprintf("The filter %s is not registered. (line no: %d)", e.filterName(), e.LineNo))
+		}
+		e.RawContent = true
+	case e.Type == TypeSuper:
+		block := e.enclosingBlock()
+		if block == nil {
+			return errors.New(fmt.Sprintf("The super element has to be in a block. (line no: %d)", e.LineNo))
+		}
+		if block.Template != nil && block.Template.Super != nil && !block.Template.Super.definesBlock(block.Name) {
+			return errors.New(fmt.Sprintf("The block %s is not defined in the super templates. (line no: %d)", block.Name, e.LineNo))
+		}
+	case e.Type == TypeEach:
+		if _, _, ok := e.eachClause(); !ok {
+			return errors.New(fmt.Sprintf("The each element has to be \"each item in pipeline\" or \"each index, item in pipeline\". (line no: %d)", e.LineNo))
+		}
+	case e.Type == TypeLiteral:
+		if len(e.Tokens) > 1 {
+			e.Tokens = []string{e.Tokens[0], strings.TrimPrefix(strings.TrimPrefix(e.Text, e.Tokens[0]), " ")}
+		}
+		if interpolated(e.literalValue()) {
+			if err := e.appendInlineChildren(e.literalValue()); err != nil {
+				return err
+			}
+			e.Tokens = e.Tokens[:1]
+		}
+	case e.Type != TypeTag || e.comment():
 	default:
-		for i, token := range e.Tokens {
-			switch {
-			case i == 0:
-				if err := e.parseFirstToken(token); err != nil {
+		if err := e.parseFirstToken(e.Tokens[0]); err != nil {
+			return err
+		}
+		delimLeft, delimRight := e.delims()
+		e.Lexemes = lex(e.Text, e.Col, delimLeft, delimRight)
+		var name string
+		var text bytes.Buffer
+		for _, t := range e.Lexemes {
+			switch t.Type {
+			case TokenAttributeName:
+				name = t.Value
+			case TokenAttributeValue:
+				if err := e.SetAttribute(name, t.Value); err != nil {
 					return err
 				}
-			case e.hasTextValues():
-				e.appendTextValue(token)
-			case attribute(token):
-				e.appendAttribute(token)
-			case singleAttribute(token):
-				e.appendSingleAttribute(token)
-			default:
-				e.appendTextValue(token)
+			case TokenBooleanAttribute:
+				e.SingleAttributes = append(e.SingleAttributes, t.Value)
+			case TokenText, TokenAction:
+				text.WriteString(t.Value)
+			}
+		}
+		if text.Len() > 0 {
+			e.appendTextValue(text.String())
+		}
+		if e.hasTextValues() && e.Tag != "doctype" && interpolated(e.textValue()) {
+			if err := e.appendInlineChildren(e.textValue()); err != nil {
+				return err
+			}
+			e.TextValues = nil
+		}
+	}
+	return nil
+}
+
+// appendInlineChildren parses the inline tags (#[tag text]) and the output
+// expressions (#{pipeline}) in the text and appends them and the rest texts
+// to the element as inline children.
+func (e *Element) appendInlineChildren(s string) error {
+	var text bytes.Buffer
+	appendText := func() {
+		if text.Len() > 0 {
+			e.AppendChild(&Element{Text: text.String(), Tokens: []string{"|", text.String()}, LineNo: e.LineNo, Indent: e.Indent, Parent: e, Attributes: make(map[string]string), Type: TypeLiteral, Inline: true})
+			text.Reset()
+		}
+	}
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], `\#[`) || strings.HasPrefix(s[i:], `\#{`):
+			text.WriteString(s[i+1 : i+3])
+			i += 3
+		case strings.HasPrefix(s[i:], "#[") || strings.HasPrefix(s[i:], "#{"):
+			end := closingBracket(s, i+1)
+			if end < 0 {
+				return errors.New(fmt.Sprintf("The inline interpolation is not closed. (line no: %d)", e.LineNo))
+			}
+			appendText()
+			inner := s[i+2 : end]
+			if s[i+1] == '{' {
+				inner = "= " + inner
 			}
+			child, err := NewElement(inner, e.LineNo, e.Indent, e, nil, nil)
+			if err != nil {
+				return err
+			}
+			child.Inline = true
+			e.AppendChild(child)
+			i = end + 1
+		default:
+			text.WriteByte(s[i])
+			i++
 		}
 	}
+	appendText()
 	return nil
 }
 
@@ -105,6 +251,7 @@ func (e *Element) parseFirstToken(token string) error {
 
 // setTag extracts a tag from the token and sets it to the element.
 func (e *Element) setTag(token string) error {
+	token = e.trimOperators(token)
 	tag := strings.Split(strings.Split(token, "#")[0], ".")[0]
 	if tag == "" {
 		tag = "div"
@@ -121,6 +268,24 @@ func (e *Element) setTag(token string) error {
 	return nil
 }
 
+// trimOperators trims the whitespace operators (< and >) and the unescaped
+// output marker (!) from the token's suffix and sets them to the element.
+func (e *Element) trimOperators(token string) string {
+	for {
+		switch {
+		case strings.HasSuffix(token, "!"):
+			e.Unescaped = true
+		case strings.HasSuffix(token, "<"):
+			e.SpaceBefore = true
+		case strings.HasSuffix(token, ">"):
+			e.SpaceAfter = true
+		default:
+			return token
+		}
+		token = token[:len(token)-1]
+	}
+}
+
 // setIdFromToken extracts an id from the token and sets it to the element.
 func (e *Element) setIdFromToken(token string) error {
 	parts := strings.Split(token, "#")
@@ -176,86 +341,139 @@ func (e *Element) appendAttribute(token string) {
 	if len(kv) < 2 {
 		return
 	}
-	k := kv[0]
-	v := parseValue(strings.Join(kv[1:], "="))
+	e.SetAttribute(kv[0], parseValue(strings.Join(kv[1:], "=")))
+}
+
+// SetAttribute sets the attribute to the element. An id attribute sets the
+// element's id and a class attribute appends the class to the element's
+// classes. The order of the attributes is kept.
+func (e *Element) SetAttribute(k, v string) error {
 	switch k {
 	case "id":
-		e.setId(v)
+		return e.setId(v)
 	case "class":
 		e.appendClass(v)
 	default:
+		if e.Attributes == nil {
+			e.Attributes = make(map[string]string)
+		}
+		if _, prs := e.Attributes[k]; !prs {
+			e.attrOrder = append(e.attrOrder, k)
+		}
 		e.Attributes[k] = v
 	}
-}
-
-func (e *Element) appendSingleAttribute(token string) {
-	e.SingleAttributes = append(e.SingleAttributes, strings.TrimSuffix(strings.TrimPrefix(token, "["), "]"))
-
+	return nil
 }
 
 // AppendChild appends the element to the receiver element.
 func (e *Element) AppendChild(child *Element) {
-	e.Children = append(e.Children, child)
+	e.Children = appendElement(e.Children, child)
 }
 
 // Html writes the element's html to the buffer.
 func (e *Element) Html(bf *bytes.Buffer, stringTemplates map[string]string) error {
+	return e.html(bf, newContext(stringTemplates))
+}
+
+// html writes the element's html to the buffer within the context.
+func (e *Element) html(bf *bytes.Buffer, ctx *context) error {
 	switch {
 	case e.comment():
 	case e.Type == TypeContent || e.Type == TypeExpression || e.Type == TypeOutputExpression:
 		g := e.getGenerator()
 		if e.Type == TypeOutputExpression {
-			bf.WriteString(g.delimLeft + strings.Join(e.Tokens[1:], " ") + g.delimRight)
+			pipeline := strings.Join(e.Tokens[1:], " ")
+			if ctx.xml {
+				pipeline = xmlAction(pipeline)
+			}
+			bf.WriteString(g.delimLeft + pipeline + g.delimRight)
+		} else if ctx.plain && e.Type == TypeExpression {
+			bf.WriteString(e.Text + " ")
+		} else if ctx.xml {
+			var text bytes.Buffer
+			e.writeText(&text)
+			bf.WriteString(escapeXMLActions(text.String(), g.delimLeft, g.delimRight))
 		} else {
 			e.writeText(bf)
 		}
 		for _, child := range e.Children {
-			err := child.Html(bf, stringTemplates)
+			err := child.html(bf, ctx)
 			if err != nil {
 				return err
 			}
 		}
 	case e.Type == TypeLiteral:
-		e.writeLiteralValue(bf)
-	case e.Type == TypeBlock:
-		if len(e.Tokens) < 2 {
-			return errors.New(fmt.Sprintf("The block element does not have a name. (line no: %d)", e.LineNo))
+		e.writeLiteralValue(bf, ctx)
+		for _, child := range e.Children {
+			if !child.Inline {
+				continue
+			}
+			if err := child.html(bf, ctx); err != nil {
+				return err
+			}
+		}
+		switch {
+		case e.Tokens[0] == "'" || (ctx.plain && !e.Inline):
+			bf.WriteString(" ")
+		case ctx.text && !ctx.xml && !e.Inline:
+			bf.WriteString("\n")
 		}
-		name := e.Tokens[1]
-		sub := e.getTemplate().Sub
-		if sub == nil {
-			if err := e.writeChildren(bf, stringTemplates); err != nil {
+	case e.Type == TypeDefine:
+		g := e.getGenerator()
+		bf.WriteString(g.delimLeft + "define " + strconv.Quote(e.templateName()) + g.delimRight)
+		if err := e.writeChildren(bf, ctx); err != nil {
+			return err
+		}
+		bf.WriteString(g.delimLeft + "end" + g.delimRight)
+	case e.Type == TypeTemplate:
+		g := e.getGenerator()
+		bf.WriteString(g.delimLeft + "template " + strconv.Quote(e.templateName()))
+		if len(e.Tokens) > 2 {
+			bf.WriteString(" " + strings.Join(e.Tokens[2:], " "))
+		}
+		bf.WriteString(g.delimRight)
+	case e.Type == TypeIf || e.Type == TypeRange || e.Type == TypeWith || e.Type == TypeEach:
+		g := e.getGenerator()
+		for branch := e; branch != nil; branch = branch.Else {
+			bf.WriteString(g.delimLeft + branch.action() + g.delimRight)
+			if err := branch.writeChildren(bf, ctx); err != nil {
 				return err
 			}
-			return nil
 		}
-		block := sub.Blocks[name]
+		bf.WriteString(g.delimLeft + "end" + g.delimRight)
+	case e.Type == TypeElse:
+		if e.Head == nil {
+			return e.elseError()
+		}
+	case e.Type == TypeCase:
+		if err := e.writeCase(bf, ctx); err != nil {
+			return err
+		}
+	case e.Type == TypeFilter:
+		bf.WriteString(e.Filtered)
+	case e.Type == TypeBlock:
+		if len(e.Tokens) < 2 {
+			return errors.New(fmt.Sprintf("The block element does not have a name. (line no: %d)", e.LineNo))
+		}
+		block := ctx.block(e.getTemplate(), e.Tokens[1])
 		if block == nil {
-			if err := e.writeChildren(bf, stringTemplates); err != nil {
+			if err := e.writeChildren(bf, ctx); err != nil {
 				return err
 			}
 			return nil
 		}
-		block.Html(bf, stringTemplates)
+		if err := block.html(bf, ctx); err != nil {
+			return err
+		}
+	case e.Type == TypeSuper:
+		if err := e.writeSuperBlock(bf, ctx); err != nil {
+			return err
+		}
 	case e.Type == TypeInclude:
 		if len(e.Tokens) < 2 {
 			return errors.New(fmt.Sprintf("The include element does not have a path. (line no: %d)", e.LineNo))
 		}
-		tpl := e.getTemplate()
-		incTplPath := e.Tokens[1]
-		g := tpl.Generator
-		var incTpl *Template
-		var err error
-		if stringTemplates == nil {
-			addBaseDir := true
-			if g.baseDir != "" && CurrentDirectoryBasedPath(incTplPath) {
-				incTplPath = tpl.Dir() + incTplPath
-				addBaseDir = false
-			}
-			incTpl, err = g.parse(incTplPath+Extension, nil, addBaseDir)
-		} else {
-			incTpl, err = g.parse(incTplPath, stringTemplates, false)
-		}
+		incTpl, err := e.includedTemplate(ctx.stringTemplates)
 		if err != nil {
 			return err
 		}
@@ -263,28 +481,100 @@ func (e *Element) Html(bf *bytes.Buffer, stringTemplates map[string]string) erro
 		if err != nil {
 			return err
 		}
-		incHtml, err := incTpl.Html(stringTemplates, embedMap)
+		if embedMap, err = incTpl.embedMap(embedMap, e); err != nil {
+			return err
+		}
+		incHtml, err := incTpl.html(ctx, embedMap)
 		if err != nil {
 			return err
 		}
-		bf.WriteString(incHtml)
+		ctx.writeInclude(bf, incHtml)
+	case ctx.plain:
+		return e.writePlainText(bf, ctx)
 	default:
-		e.writeOpenTag(bf)
+		if e.SpaceBefore {
+			bf.WriteString(" ")
+		}
+		e.writeOpenTag(bf, ctx)
 		if e.hasTextValues() {
-			e.writeTextValue(bf)
+			e.writeTextValue(bf, ctx)
 		}
-		if err := e.writeChildren(bf, stringTemplates); err != nil {
-			return err
+		switch {
+		case e.RawContent && e.preformatted():
+			e.writePreformattedContent(bf)
+		case e.RawContent && e.minify():
+			e.writeMinifiedContent(bf)
+		default:
+			if err := e.writeChildren(bf, ctx); err != nil {
+				return err
+			}
+		}
+		e.writeCloseTag(bf, ctx)
+		if e.SpaceAfter {
+			bf.WriteString(" ")
 		}
-		e.writeCloseTag(bf)
 	}
 	return nil
 }
 
+// includedTemplate parses the template which the include element includes
+// and returns it.
+func (e *Element) includedTemplate(stringTemplates map[string]string) (*Template, error) {
+	incTplPath, addBaseDir := e.includedTemplatePath(stringTemplates)
+	return e.getGenerator().parse(incTplPath, stringTemplates, addBaseDir)
+}
+
+// includedTemplatePath returns the path of the template which the include
+// element includes and if the base directory has to be added to the path or
+// not.
+func (e *Element) includedTemplatePath(stringTemplates map[string]string) (string, bool) {
+	tpl := e.getTemplate()
+	incTplPath := e.Tokens[1]
+	if stringTemplates != nil {
+		return incTplPath, false
+	}
+	addBaseDir := true
+	if tpl != nil && tpl.Generator != nil && tpl.Generator.baseDir != "" && CurrentDirectoryBasedPath(incTplPath) {
+		incTplPath = tpl.Dir() + incTplPath
+		addBaseDir = false
+	}
+	return incTplPath + Extension, addBaseDir
+}
+
+// writeSuperBlock writes the HTML of the super templates' version of the
+// block which the super element is in. It is the nearest super template's
+// block which has the same name or the children of the block element.
+func (e *Element) writeSuperBlock(bf *bytes.Buffer, ctx *context) error {
+	block := e.enclosingBlock()
+	if block == nil || block.Template == nil {
+		return errors.New(fmt.Sprintf("The super element has to be in a block. (line no: %d)", e.LineNo))
+	}
+	for super := block.Template.Super; super != nil; super = super.Super {
+		if superBlock, prs := super.Blocks[block.Name]; prs {
+			return superBlock.html(bf, ctx)
+		}
+		if blockElement := super.blockElement(block.Name); blockElement != nil {
+			return blockElement.writeChildren(bf, ctx)
+		}
+	}
+	return errors.New(fmt.Sprintf("The block %s is not defined in the super templates. (line no: %d)", block.Name, e.LineNo))
+}
+
+// enclosingBlock returns the block which the element is in or nil. The
+// element is not in a block if it is in a block element.
+func (e *Element) enclosingBlock() *Block {
+	for ; e.Parent != nil; e = e.Parent {
+		if e.Parent.Type == TypeBlock {
+			return nil
+		}
+	}
+	return e.Block
+}
+
 // writeChildren writes the element's children's HTML.
-func (e *Element) writeChildren(bf *bytes.Buffer, stringTemplates map[string]string) error {
+func (e *Element) writeChildren(bf *bytes.Buffer, ctx *context) error {
 	for _, child := range e.Children {
-		err := child.Html(bf, stringTemplates)
+		err := child.html(bf, ctx)
 		if err != nil {
 			return err
 		}
@@ -292,17 +582,14 @@ func (e *Element) writeChildren(bf *bytes.Buffer, stringTemplates map[string]str
 	return nil
 }
 
-// writeOpenTag writes the element's open tag to the buffer.
-func (e *Element) writeOpenTag(bf *bytes.Buffer) {
+// writeOpenTag writes the element's open tag to the buffer. A doctype
+// element selects the output mode of the context.
+func (e *Element) writeOpenTag(bf *bytes.Buffer, ctx *context) {
 	switch e.Tag {
 	case "doctype":
-		if doctype, prs := doctypes[e.textValue()]; prs {
-			bf.WriteString(doctype)
-		} else {
-			bf.WriteString("<!DOCTYPE ")
-			bf.WriteString(e.textValue())
-			bf.WriteString(">")
-		}
+		doctype := e.doctype()
+		ctx.xhtml = xhtmlDoctype(doctype)
+		bf.WriteString(doctype)
 	default:
 		bf.WriteString("<")
 		bf.WriteString(e.Tag)
@@ -313,17 +600,126 @@ func (e *Element) writeOpenTag(bf *bytes.Buffer) {
 			e.writeClasses(bf)
 		}
 		if e.hasAttributes() {
-			e.writeAttributes(bf)
+			e.writeAttributes(bf, ctx)
 		}
 		if e.hasSingleAttributes() {
-			e.writeSingleAttributes(bf)
+			e.writeSingleAttributes(bf, ctx)
+		}
+		if e.selfClosing(ctx) {
+			bf.WriteString(" />")
+		} else {
+			bf.WriteString(">")
+		}
+	}
+}
+
+// selfClosing returns if the element's open tag closes itself or not. Void
+// elements close themselves in XHTML and empty elements do in XML.
+func (e *Element) selfClosing(ctx *context) bool {
+	if ctx.xml {
+		return !e.hasTextValues() && len(e.Children) == 0
+	}
+	return ctx.xhtml && voidElements[e.Tag]
+}
+
+// doctype returns the declaration of the doctype element. The doctypes
+// registered to the generator take precedence over the built-in ones.
+func (e *Element) doctype() string {
+	name := e.textValue()
+	if tpl := e.getTemplate(); tpl != nil && tpl.Generator != nil {
+		if doctype, prs := tpl.Generator.doctypes[name]; prs {
+			return doctype
+		}
+	}
+	if doctype, prs := doctypes[name]; prs {
+		return doctype
+	}
+	return "<!DOCTYPE " + name + ">"
+}
+
+// xhtmlDoctype returns if the doctype declaration is based on XML or not.
+func xhtmlDoctype(doctype string) bool {
+	return strings.HasPrefix(doctype, "<?xml") || strings.Contains(doctype, "XHTML") || strings.Contains(doctype, "SVG") || strings.Contains(doctype, "PLIST")
+}
+
+// writeMinifiedContent writes the raw content element's minified content
+// lines to the buffer. The contents of script and style elements are
+// minified only if the generator minifies raw contents.
+func (e *Element) writeMinifiedContent(bf *bytes.Buffer) {
+	g := e.getGenerator()
+	lines := e.contentLines()
+	switch {
+	case (e.Tag == "script" || e.Tag == "style") && !g.minifyRawContent:
+		bf.WriteString(strings.Join(lines, "\n") + "\n")
+	case e.Tag == "script":
+		bf.WriteString(minifyJS(lines))
+	case e.Tag == "style":
+		bf.WriteString(minifyCSS(strings.Join(lines, "\n"), g.delimLeft, g.delimRight))
+	default:
+		var text string
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "":
+			case text == "" || strings.HasSuffix(text, "\\"):
+				text = strings.TrimSuffix(text, "\\") + line
+			default:
+				text += " " + line
+			}
+		}
+		bf.WriteString(collapseWhitespace(strings.TrimSuffix(text, "\\"), g.delimLeft, g.delimRight))
+	}
+}
+
+// writePreformattedContent writes the raw content element's content lines
+// dedented relative to the element's indent to the buffer.
+func (e *Element) writePreformattedContent(bf *bytes.Buffer) {
+	lines := e.contentLines()
+	for i, line := range lines {
+		lines[i] = dedent(line, e.Indent+1)
+	}
+	bf.WriteString(strings.Join(lines, "\n"))
+}
+
+// contentLines returns the texts of the element's descendant content
+// elements.
+func (e *Element) contentLines() []string {
+	var lines []string
+	for _, child := range e.Children {
+		lines = append(lines, child.Text)
+		lines = append(lines, child.contentLines()...)
+	}
+	return lines
+}
+
+// minify returns if the element's HTML is minified or not.
+func (e *Element) minify() bool {
+	tpl := e.getTemplate()
+	return tpl != nil && tpl.Generator != nil && tpl.Generator.minify && !e.preformatted()
+}
+
+// preformatted returns if the element or its ancestors preserve whitespaces
+// or not.
+func (e *Element) preformatted() bool {
+	for p := e; p != nil; p = p.Parent {
+		if preformattedTags[p.Tag] {
+			return true
 		}
-		bf.WriteString(">")
 	}
+	return false
 }
 
-// writeText writes the element's text to the buffer.
+// writeText writes the element's text to the buffer. A trailing backslash
+// trims the line feed.
 func (e *Element) writeText(bf *bytes.Buffer) {
+	if text := strings.TrimRight(e.Text, " \t"); e.Type == TypeContent && strings.HasSuffix(text, "\\") {
+		bf.WriteString(strings.TrimSuffix(text, "\\"))
+		return
+	}
+	if e.Type == TypeExpression && e.minify() {
+		bf.WriteString(e.Text)
+		return
+	}
 	bf.WriteString(e.Text + "\n")
 }
 
@@ -339,9 +735,7 @@ func (e *Element) hasId() bool {
 
 // writeId writes the element's id to the buffer.
 func (e *Element) writeId(bf *bytes.Buffer) {
-	bf.WriteString(" id=\"")
-	bf.WriteString(e.Id)
-	bf.WriteString("\"")
+	e.writeAttribute(bf, "id", e.Id)
 }
 
 // hasClasses returns if the element has classes or not.
@@ -351,14 +745,7 @@ func (e *Element) hasClasses() bool {
 
 // writeClasses writes the element's classes to the buffer.
 func (e *Element) writeClasses(bf *bytes.Buffer) {
-	bf.WriteString(" class=\"")
-	for i, class := range e.Classes {
-		if i > 0 {
-			bf.WriteString(" ")
-		}
-		bf.WriteString(class)
-	}
-	bf.WriteString("\"")
+	e.writeAttribute(bf, "class", strings.Join(e.Classes, " "))
 }
 
 // hasAttributes returns if the element has attributes or not.
@@ -372,38 +759,94 @@ func (e *Element) hasSingleAttributes() bool {
 }
 
 // writeAttributes writes the element's attributes to the buffer.
-func (e *Element) writeAttributes(bf *bytes.Buffer) {
-	for k, v := range e.Attributes {
-		bf.WriteString(" ")
-		bf.WriteString(k)
-		bf.WriteString("=\"")
+func (e *Element) writeAttributes(bf *bytes.Buffer, ctx *context) {
+	for _, k := range e.attributeNames() {
+		v := e.Attributes[k]
+		if ctx.xml {
+			g := e.getGenerator()
+			v = escapeXMLActions(v, g.delimLeft, g.delimRight)
+		}
+		e.writeAttribute(bf, k, v)
+	}
+}
+
+// attributeNames returns the names of the element's attributes in the order
+// in which they are set. The names which were not set by SetAttribute follow
+// in the sorted order.
+func (e *Element) attributeNames() []string {
+	names := make([]string, 0, len(e.Attributes))
+	set := make(map[string]bool)
+	for _, k := range e.attrOrder {
+		if _, prs := e.Attributes[k]; prs && !set[k] {
+			names = append(names, k)
+			set[k] = true
+		}
+	}
+	var rest []string
+	for k := range e.Attributes {
+		if !set[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+// writeAttribute writes the attribute to the buffer. The quotes of the value
+// are omitted if the generator minifies HTML and they are optional.
+func (e *Element) writeAttribute(bf *bytes.Buffer, k, v string) {
+	delimLeft, delimRight := e.delims()
+	v = escapeAttribute(v, delimLeft, delimRight)
+	bf.WriteString(" ")
+	bf.WriteString(k)
+	bf.WriteString("=")
+	if e.minify() && unquotable(v, delimLeft) {
 		bf.WriteString(v)
-		bf.WriteString("\"")
+		return
 	}
+	bf.WriteString("\"")
+	bf.WriteString(v)
+	bf.WriteString("\"")
 }
 
 // writeSingleAttributes writes the element's single attributes to the buffer.
-func (e *Element) writeSingleAttributes(bf *bytes.Buffer) {
+// The attributes are written with their names as values in XHTML and XML.
+func (e *Element) writeSingleAttributes(bf *bytes.Buffer, ctx *context) {
 	for _, v := range e.SingleAttributes {
+		if ctx.xhtml || ctx.xml {
+			bf.WriteString(" " + v + "=\"" + v + "\"")
+			continue
+		}
 		bf.WriteString(" ")
 		bf.WriteString(v)
 	}
 }
 
 // writeTextValue writes the element's text value to the buffer.
-func (e *Element) writeTextValue(bf *bytes.Buffer) {
-	switch e.Tag {
-	case "doctype":
-	default:
-		bf.WriteString(e.textValue())
+func (e *Element) writeTextValue(bf *bytes.Buffer, ctx *context) {
+	if e.Tag == "doctype" {
+		return
 	}
+	delimLeft, delimRight := e.delims()
+	text := e.textValue()
+	switch {
+	case ctx.xml:
+		text = escapeXMLActions(text, delimLeft, delimRight)
+	case e.minify():
+		text = strings.TrimSpace(collapseWhitespace(text, delimLeft, delimRight))
+	}
+	if e.escaped(ctx) {
+		text = escapeText(text, delimLeft, delimRight)
+	}
+	bf.WriteString(text)
 }
 
 // writeCloseTag writes the element's close tag to the buffer.
-func (e *Element) writeCloseTag(bf *bytes.Buffer) {
-	switch e.Tag {
-	case "doctype":
-	case "br":
+func (e *Element) writeCloseTag(bf *bytes.Buffer, ctx *context) {
+	switch {
+	case e.Tag == "doctype":
+	case e.selfClosing(ctx):
+	case !ctx.xml && voidElements[e.Tag]:
 	default:
 		bf.WriteString("</")
 		bf.WriteString(e.Tag)
@@ -420,8 +863,23 @@ func (e *Element) setType() {
 		e.Type = TypeBlock
 	case len(e.Tokens) > 0 && e.Tokens[0] == "include":
 		e.Type = TypeInclude
-	case len(e.Tokens) > 0 && e.Tokens[0] == "|":
+	case len(e.Tokens) > 0 && (e.Tokens[0] == TypeIf || e.Tokens[0] == TypeElse || e.Tokens[0] == TypeRange || e.Tokens[0] == TypeWith || e.Tokens[0] == TypeEach):
+		e.Type = e.Tokens[0]
+	case len(e.Tokens) > 0 && (e.Tokens[0] == TypeCase || e.Tokens[0] == TypeWhen || e.Tokens[0] == TypeDefault):
+		e.Type = e.Tokens[0]
+	case len(e.Tokens) > 0 && len(e.Tokens[0]) > 1 && strings.HasPrefix(e.Tokens[0], ":"):
+		e.Type = TypeFilter
+	case len(e.Tokens) > 0 && e.Tokens[0] == "define":
+		e.Type = TypeDefine
+	case len(e.Tokens) == 1 && e.Tokens[0] == "super":
+		e.Type = TypeSuper
+	case len(e.Tokens) > 1 && e.Tokens[0] == "template" && literal(e.Tokens[1]):
+		e.Type = TypeTemplate
+	case len(e.Tokens) > 0 && (e.Tokens[0] == "|" || e.Tokens[0] == "'"):
+		e.Type = TypeLiteral
+	case len(e.Tokens) > 0 && e.Tokens[0] == "!":
 		e.Type = TypeLiteral
+		e.Unescaped = true
 	case expression(e.Text, e.getGenerator()):
 		e.Type = TypeExpression
 	case len(e.Tokens) > 0 && e.Tokens[0] == "=":
@@ -455,9 +913,191 @@ func (e *Element) literalValue() string {
 	return strings.Join(e.Tokens[1:], " ")
 }
 
+// action returns the action which the control element opens.
+func (e *Element) action() string {
+	switch e.Type {
+	case TypeEach:
+		vars, pipeline, _ := e.eachClause()
+		return "range " + strings.Join(vars, ", ") + " := " + pipeline
+	default:
+		return e.Type + strings.TrimPrefix(e.Text, e.Tokens[0])
+	}
+}
+
+// eachClause parses the each element and returns its variables and pipeline.
+func (e *Element) eachClause() ([]string, string, bool) {
+	clause := strings.TrimSpace(strings.TrimPrefix(e.Text, e.Tokens[0]))
+	parts := strings.SplitN(clause, " in ", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+		return nil, "", false
+	}
+	var vars []string
+	for _, v := range strings.Split(parts[0], ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "$")
+		if v == "" || strings.ContainsAny(v, " .") {
+			return nil, "", false
+		}
+		vars = append(vars, "$"+v)
+	}
+	if len(vars) > 2 {
+		return nil, "", false
+	}
+	return vars, strings.TrimSpace(parts[1]), true
+}
+
+// whenValues parses the when element and returns its comma-separated values.
+// Single-quoted values are rewritten as double-quoted strings because Go
+// templates read them as rune constants.
+func (e *Element) whenValues() ([]string, error) {
+	values := splitValues(strings.TrimPrefix(e.Text, e.Tokens[0]))
+	for i, v := range values {
+		if v == "" {
+			return nil, errors.New(fmt.Sprintf("The when element has an empty value. (line no: %d)", e.LineNo))
+		}
+		if len(v) > 1 && v[0] == '\'' && v[len(v)-1] == '\'' {
+			values[i] = strconv.Quote(unquote(v))
+		}
+	}
+	return values, nil
+}
+
+// writeCase writes the case element's when and default children as if
+// actions to the buffer.
+func (e *Element) writeCase(bf *bytes.Buffer, ctx *context) error {
+	g := e.getGenerator()
+	pipeline := strings.TrimSpace(strings.TrimPrefix(e.Text, e.Tokens[0]))
+	if strings.Contains(pipeline, " ") {
+		pipeline = "(" + pipeline + ")"
+	}
+	opened := false
+	for _, child := range e.Children {
+		switch child.Type {
+		case TypeWhen:
+			action := "if"
+			if opened {
+				action = "else if"
+			}
+			bf.WriteString(g.delimLeft + action + " eq " + pipeline + " " + strings.Join(child.TextValues, " ") + g.delimRight)
+			opened = true
+		case TypeDefault:
+			if opened {
+				bf.WriteString(g.delimLeft + "else" + g.delimRight)
+			}
+		default:
+			continue
+		}
+		if err := child.writeChildren(bf, ctx); err != nil {
+			return err
+		}
+	}
+	if opened {
+		bf.WriteString(g.delimLeft + "end" + g.delimRight)
+	}
+	return nil
+}
+
+// validateCase validates the case element's children.
+func (e *Element) validateCase() error {
+	values := make(map[string]bool)
+	hasDefault := false
+	for _, child := range e.Children {
+		switch {
+		case child.comment():
+			continue
+		case hasDefault:
+			return errors.New(fmt.Sprintf("The default element has to be the last child of the case element. (line no: %d)", child.LineNo))
+		case child.Type == TypeDefault:
+			hasDefault = true
+		case child.Type == TypeWhen:
+			for _, v := range child.TextValues {
+				if values[v] {
+					return errors.New(fmt.Sprintf("The when element has a duplicated value %s. (line no: %d)", v, child.LineNo))
+				}
+				values[v] = true
+			}
+		default:
+			return errors.New(fmt.Sprintf("The case element can have only when and default elements. (line no: %d)", child.LineNo))
+		}
+	}
+	return nil
+}
+
+// filterName returns the name of the filter element's filter.
+func (e *Element) filterName() string {
+	return strings.TrimPrefix(e.Tokens[0], ":")
+}
+
+// applyFilter applies the filter to the filter element's content lines
+// dedented relative to the element's indent.
+func (e *Element) applyFilter() error {
+	lines := e.contentLines()
+	for i, line := range lines {
+		lines[i] = dedent(line, e.Indent+1)
+	}
+	b, err := e.getGenerator().filter(e.filterName())([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		return errors.New(fmt.Sprintf("The filter %s returned an error: %s (line no: %d)", e.filterName(), err.Error(), e.LineNo))
+	}
+	e.Filtered = string(b)
+	return nil
+}
+
+// control returns if the element opens a control structure or not.
+func (e *Element) control() bool {
+	return e.Type == TypeIf || e.Type == TypeRange || e.Type == TypeWith || e.Type == TypeEach
+}
+
+// elseError returns an error of the else element which does not follow a
+// control element.
+func (e *Element) elseError() error {
+	return errors.New(fmt.Sprintf("The else element has to follow an if, range, with or each element. (line no: %d)", e.LineNo))
+}
+
+// templateName returns the name of the define or template element.
+func (e *Element) templateName() string {
+	if len(e.Tokens) < 2 {
+		return ""
+	}
+	return parseValue(e.Tokens[1])
+}
+
 // writeLiteralValue writes the element's literal value to the buffer.
-func (e *Element) writeLiteralValue(bf *bytes.Buffer) {
-	bf.WriteString(e.literalValue())
+func (e *Element) writeLiteralValue(bf *bytes.Buffer, ctx *context) {
+	delimLeft, delimRight := e.delims()
+	value := e.literalValue()
+	if ctx.xml {
+		value = escapeXMLActions(value, delimLeft, delimRight)
+	}
+	if e.escaped(ctx) {
+		value = escapeText(value, delimLeft, delimRight)
+	}
+	bf.WriteString(value)
+}
+
+// escaped returns if the element's texts are escaped or not. The texts are
+// not escaped for the text backends or if the element or its inline parent
+// has the unescaped output marker.
+func (e *Element) escaped(ctx *context) bool {
+	if ctx.text && !ctx.xml {
+		return false
+	}
+	for p := e; p != nil; p = p.Parent {
+		if p.Unescaped {
+			return false
+		}
+		if !p.Inline {
+			break
+		}
+	}
+	return true
+}
+
+// delims returns the delimiters of the element's generator.
+func (e *Element) delims() (string, string) {
+	if tpl := e.getTemplate(); tpl != nil && tpl.Generator != nil {
+		return tpl.Generator.delimLeft, tpl.Generator.delimRight
+	}
+	return defaultDelimLeft, defaultDelimRight
 }
 
 // comment returns if the string is a comment or not.
@@ -465,84 +1105,176 @@ func (e *Element) comment() bool {
 	return strings.HasPrefix(e.Text, "//")
 }
 
+// validate validates the element after its children are appended.
+func (e *Element) validate() error {
+	if e.Nested != nil {
+		if err := e.Nested.validate(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case e.Type == TypeElse && e.Head == nil:
+		return e.elseError()
+	case e.Type == TypeCase:
+		return e.validateCase()
+	case e.Type == TypeFilter:
+		return e.applyFilter()
+	}
+	return nil
+}
+
 // NewElement generates a new element and returns it.
 func NewElement(text string, lineNo int, indent int, parent *Element, tpl *Template, block *Block) (*Element, error) {
+	return newElement(text, lineNo, indent, len(text)-len(strings.TrimLeft(text, " \t"))+1, parent, tpl, block)
+}
+
+// newElement generates a new element whose text starts at the column and
+// returns it.
+func newElement(text string, lineNo int, indent int, col int, parent *Element, tpl *Template, block *Block) (*Element, error) {
 	rawText := text
 	text = strings.TrimSpace(text)
-	tokens := tokens(text)
-	e := &Element{Text: text, Tokens: tokens, LineNo: lineNo, Indent: indent, Parent: parent, Attributes: make(map[string]string), Template: tpl, Block: block}
+	e := &Element{Text: text, LineNo: lineNo, Indent: indent, Col: col, Parent: parent, Attributes: make(map[string]string), Template: tpl, Block: block}
+	delimLeft, delimRight := e.delims()
+	e.Tokens = words(text, delimLeft, delimRight)
 	e.setType()
 	if e.Type == TypeContent {
 		e.Text = rawText
 	}
+	var nestedText string
+	if e.Type == TypeTag && blockExpansion(e.Tokens) {
+		nestedText = strings.TrimSpace(strings.TrimPrefix(text, e.Tokens[0]))
+		e.Text = strings.TrimSuffix(e.Tokens[0], ":")
+		e.Tokens = []string{e.Text}
+	}
 	err := e.parse()
 	if err != nil {
 		return nil, err
 	}
+	if nestedText != "" {
+		nested, err := newElement(nestedText, lineNo, indent, col+len(text)-len(nestedText), e, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		e.AppendChild(nested)
+		e.Nested = nested
+	}
 	return e, nil
 }
 
-// tokens returns the string's tokens.
-func tokens(s string) []string {
-	tokens := make([]string, 0)
-	var joinedTokens []string
-	joined := false
-	var closeMark string
-	for _, token := range strings.Split(s, " ") {
-		if joined {
-			joinedTokens = append(joinedTokens, token)
-			if closed(token, closeMark) {
-				tokens = append(tokens, strings.Join(joinedTokens, " "))
-				joinedTokens = make([]string, 0)
-				joined = false
+// innermost returns the innermost element which is nested in the element by
+// the block expansion.
+func (e *Element) innermost() *Element {
+	for e.Nested != nil {
+		e = e.Nested
+	}
+	return e
+}
+
+// appendElement appends the element to the elements. An else element is
+// chained to the control element which it follows.
+func appendElement(elements []*Element, e *Element) []*Element {
+	if e.Type == TypeElse && len(elements) > 0 {
+		prev := elements[len(elements)-1]
+		head := prev
+		if prev.Type == TypeElse {
+			head = prev.Head
+		}
+		if head != nil && head.control() {
+			tail := head
+			for tail.Else != nil {
+				tail = tail.Else
 			}
-		} else {
-			if unclosed, mark := unclosed(token); unclosed {
-				joined = true
-				joinedTokens = []string{token}
-				closeMark = mark
-			} else {
-				tokens = append(tokens, token)
+			elseIf := len(e.Tokens) > 1
+			if (tail == head || len(tail.Tokens) > 1) && (!elseIf || head.Type == TypeIf) {
+				tail.Else = e
+				e.Head = head
 			}
 		}
 	}
-	if joined {
-		tokens = append(tokens, joinedTokens...)
-	}
-	return tokens
+	return append(elements, e)
 }
 
-// unclosed returns if the token is unclosed or not.
-func unclosed(token string) (bool, string) {
-	if len(strings.Split(token, "\"")) == 2 {
-		return true, `"`
-	}
-	if strings.HasPrefix(token, "[") && !strings.HasSuffix(token, "]") {
-		return true, "]"
+// splitValues splits the string by commas which are not quoted and returns
+// the trimmed values.
+func splitValues(s string) []string {
+	var values []string
+	var quote rune
+	start := 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote && (quote == '`' || i == 0 || s[i-1] != '\\') {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == ',':
+			values = append(values, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
 	}
+	return append(values, strings.TrimSpace(s[start:]))
+}
 
-	return false, ""
+// dedent removes the indents up to the number from the string.
+func dedent(s string, n int) string {
+	i := 0
+	space := false
+	for ; i < len(s) && n > 0; i++ {
+		switch s[i] {
+		case unicodeTab:
+			n--
+		case unicodeSpace:
+			if space {
+				n--
+			}
+			space = !space
+		default:
+			return s[i:]
+		}
+	}
+	return s[i:]
 }
 
-// unclosed returns if the token is closed or not.
-func closed(token string, closeMark string) bool {
-	return strings.HasSuffix(token, closeMark)
+// interpolated returns if the string has inline interpolations or not.
+func interpolated(s string) bool {
+	return strings.Contains(s, "#[") || strings.Contains(s, "#{")
 }
 
-// attribute returns if the token is a attribute set or not.
-func attribute(token string) bool {
-	return strings.Index(token, "=") >= 0
+// closingBracket returns the index of the bracket which closes the bracket
+// at the index or -1.
+func closingBracket(s string, i int) int {
+	open := s[i]
+	close := byte(']')
+	if open == '{' {
+		close = '}'
+	}
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
 }
 
-// singleAttribute returns if the token is a single attribute set or not.
-func singleAttribute(token string) bool {
-	return strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]")
+// blockExpansion returns if the tokens start with a tag which is followed by
+// a colon and a nested element or not.
+func blockExpansion(tokens []string) bool {
+	return len(tokens) > 1 && len(tokens[0]) > 1 && strings.HasSuffix(tokens[0], ":") && tokens[0] != "javascript:" && !strings.HasPrefix(tokens[0], "//")
 }
 
-// parseValue parses the value and returns a result string.
+// parseValue parses the value and returns a result string. The quotes which
+// enclose the value are removed and the escaped quotes are unescaped.
 func parseValue(value string) string {
-	if literal(value) {
-		return value[1 : len(value)-1]
+	if literal(value) || len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return unquote(value)
 	}
 	return value
 }
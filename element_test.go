@@ -56,6 +56,88 @@ func TestElementParse(t *testing.T) {
 	}
 }
 
+func TestElementParseDefine(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	// When the define element has no name.
+	_, err := NewElement("define", 1, 0, nil, tpl, nil)
+	expectedErrMsg := "The define element has to have one name. (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the define element is not a top element.
+	parent, err := NewElement("div", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	_, err = NewElement("define row", 2, 1, parent, nil, nil)
+	expectedErrMsg = "The define element has to be a top element. (line no: 2)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
+
+func TestElementParseControl(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	// When the if element has no pipeline.
+	_, err := NewElement("if", 1, 0, nil, tpl, nil)
+	expectedErrMsg := "The if element does not have a pipeline. (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the else element has an invalid clause.
+	_, err = NewElement("else .X", 1, 0, nil, tpl, nil)
+	expectedErrMsg = `The else element has to be "else" or "else if pipeline". (line no: 1)`
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the each element has an invalid clause.
+	_, err = NewElement("each .Items", 1, 0, nil, tpl, nil)
+	expectedErrMsg = `The each element has to be "each item in pipeline" or "each index, item in pipeline". (line no: 1)`
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
+
+func TestElementParseCase(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	// When the when element is not a child of a case element.
+	_, err := NewElement(`when "a"`, 1, 0, nil, tpl, nil)
+	expectedErrMsg := "The when element has to be a child of a case element. (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the when element has an empty value.
+	parent, err := NewElement("case .X", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	_, err = NewElement(`when "a",`, 2, 1, parent, nil, nil)
+	expectedErrMsg = "The when element has an empty value. (line no: 2)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the case element has a default element which is not the last child.
+	for i, line := range []string{"default", `when "a"`} {
+		e, err := NewElement(line, i+2, 1, parent, nil, nil)
+		if err != nil {
+			t.Errorf("An error(%s) occurred.", err.Error())
+		}
+		parent.AppendChild(e)
+	}
+	expectedErrMsg = "The default element has to be the last child of the case element. (line no: 3)"
+	if err := parent.validate(); err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
+
 func TestElementHasNoTokens(t *testing.T) {
 	// When an element has no tokens.
 	e := &Element{Tokens: nil}
@@ -135,6 +217,15 @@ func TestElementSetTag(t *testing.T) {
 		t.Errorf("No errors occurred.")
 	}
 
+	// When a tag has a namespace prefix.
+	e = &Element{Attributes: make(map[string]string)}
+	if err := e.setTag("svg:rect#id.class"); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if e.Tag != "svg:rect" || e.Id != "id" || len(e.Classes) != 1 || e.Classes[0] != "class" {
+		t.Errorf("Tag, Id and Classes should be svg:rect, id and [class]. [actual: %s, %s, %v]", e.Tag, e.Id, e.Classes)
+	}
+
 	// When a tag is "script".
 	e = &Element{Attributes: make(map[string]string)}
 	if err := e.setTag("script"); err != nil {
@@ -335,13 +426,15 @@ func TestElementHtml(t *testing.T) {
 	}
 
 	// When the element's type is block and the template's sub's block is nil.
-	tpl = &Template{Sub: &Template{Blocks: make(map[string]*Block), Generator: g}, Generator: g}
+	tpl = &Template{Generator: g}
+	ctx := newContext(nil)
+	ctx.subs = []*Template{{Blocks: make(map[string]*Block), Generator: g, Super: tpl}}
 	e, err = NewElement("block test", 1, 0, nil, tpl, nil)
 	if err != nil {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 	bf = bytes.Buffer{}
-	if err := e.Html(&bf, nil); err != nil {
+	if err := e.html(&bf, ctx); err != nil {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 
@@ -352,13 +445,15 @@ func TestElementHtml(t *testing.T) {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 	block.AppendChild(blockElement)
-	tpl = &Template{Sub: &Template{Blocks: map[string]*Block{"test": block}}, Generator: g}
+	tpl = &Template{Generator: g}
+	ctx = newContext(nil)
+	ctx.subs = []*Template{{Blocks: map[string]*Block{"test": block}, Super: tpl}}
 	e, err = NewElement("block test", 1, 0, nil, tpl, nil)
 	if err != nil {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 	bf = bytes.Buffer{}
-	if err := e.Html(&bf, nil); err != nil {
+	if err := e.html(&bf, ctx); err != nil {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 	expectedString := `<div id="id" class="class" attr="val">This is a text.</div>`
@@ -466,7 +561,9 @@ func TestElementHtml(t *testing.T) {
 	g = NewGenerator(false)
 	parentTpl := NewTemplate("./test/TestElementHtml/003.gold", g)
 	subTpl := NewTemplate("./test/TestElementHtml/003.gold", g)
-	parentTpl.Sub = subTpl
+	subTpl.Super = parentTpl
+	ctx = newContext(nil)
+	ctx.subs = []*Template{subTpl}
 	parent, err = NewElement("block test", 1, 0, nil, parentTpl, nil)
 	if err != nil {
 		t.Errorf("An error(%s) occurred.", err.Error())
@@ -475,7 +572,7 @@ func TestElementHtml(t *testing.T) {
 	parent.AppendChild(child)
 	bf = bytes.Buffer{}
 	expectedErrMsg = "The block element does not have a name. (line no: 2)"
-	if err := parent.Html(&bf, nil); err == nil || err.Error() != expectedErrMsg {
+	if err := parent.html(&bf, ctx); err == nil || err.Error() != expectedErrMsg {
 		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
 	}
 
@@ -493,6 +590,95 @@ func TestElementHtml(t *testing.T) {
 	}
 }
 
+func TestElementHtmlDefineAndTemplate(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	// When the element's type is define.
+	e, err := NewElement("define row", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	child, err := NewElement("td {{.}}", 2, 1, e, nil, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	e.AppendChild(child)
+	var bf bytes.Buffer
+	if err := e.Html(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `{{define "row"}}<td>{{.}}</td>{{end}}`
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s", expectedString)
+	}
+
+	// When the element's type is template.
+	e, err = NewElement(`template "row" .Row`, 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	bf = bytes.Buffer{}
+	if err := e.Html(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `{{template "row" .Row}}`
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s", expectedString)
+	}
+
+	// When the element is a template tag.
+	for _, line := range []string{"template#row", "template Hello", "template row .Row", "template id=row", "template [hidden]"} {
+		e, err = NewElement(line, 1, 0, nil, tpl, nil)
+		if err != nil {
+			t.Errorf("An error(%s) occurred.", err.Error())
+		}
+		if e.Type != TypeTag {
+			t.Errorf("Type should be %s", TypeTag)
+		}
+	}
+}
+
+func TestElementHtmlControl(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	parent, err := NewElement("div", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	for i, line := range []string{"with .User", "p {{.Name}}", "else", "p Guest"} {
+		indent := 1
+		p := parent
+		if i%2 == 1 {
+			indent = 2
+			p = parent.Children[len(parent.Children)-1]
+		}
+		e, err := NewElement(line, i+2, indent, p, nil, nil)
+		if err != nil {
+			t.Errorf("An error(%s) occurred.", err.Error())
+		}
+		p.AppendChild(e)
+	}
+	var bf bytes.Buffer
+	if err := parent.Html(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<div>{{with .User}}<p>{{.Name}}</p>{{else}}<p>Guest</p>{{end}}</div>"
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s", expectedString)
+	}
+
+	// When the else element does not follow a control element.
+	e, err := NewElement("else", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	bf = bytes.Buffer{}
+	expectedErrMsg := "The else element has to follow an if, range, with or each element. (line no: 1)"
+	if err := e.Html(&bf, nil); err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
+
 func TestElementWriteOpenTag(t *testing.T) {
 	g := NewGenerator(false)
 	tpl := NewTemplate("/", g)
@@ -502,19 +688,47 @@ func TestElementWriteOpenTag(t *testing.T) {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 	bf := bytes.Buffer{}
-	e.writeOpenTag(&bf)
+	e.writeOpenTag(&bf, newContext(nil))
 	expectedString := `<!DOCTYPE html>`
 	if bf.String() != expectedString {
 		t.Errorf("Buffer stirng should be %s", expectedString)
 	}
 
+	// When element's tag is doctype and a text value is an alias.
+	e, err = NewElement("doctype 5", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	bf = bytes.Buffer{}
+	e.writeOpenTag(&bf, newContext(nil))
+	expectedString = `<!DOCTYPE html>`
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s", expectedString)
+	}
+
+	// When element's tag is doctype and a text value is svg.
+	e, err = NewElement("doctype svg", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	bf = bytes.Buffer{}
+	ctx := newContext(nil)
+	e.writeOpenTag(&bf, ctx)
+	expectedString = doctypes["svg"]
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s", expectedString)
+	}
+	if !ctx.xhtml {
+		t.Errorf("The context should be XHTML.")
+	}
+
 	// When element's tag is doctype and the element has a custom text value.
 	e, err = NewElement("doctype AABBCC", 1, 0, nil, tpl, nil)
 	if err != nil {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 	bf = bytes.Buffer{}
-	e.writeOpenTag(&bf)
+	e.writeOpenTag(&bf, newContext(nil))
 	expectedString = `<!DOCTYPE AABBCC>`
 	if bf.String() != expectedString {
 		t.Errorf("Buffer stirng should be %s", expectedString)
@@ -526,7 +740,7 @@ func TestElementWriteOpenTag(t *testing.T) {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 	bf = bytes.Buffer{}
-	e.writeOpenTag(&bf)
+	e.writeOpenTag(&bf, newContext(nil))
 	expectedString = `<div id="id" class="class" attr="val">`
 	if bf.String() != expectedString {
 		t.Errorf("Buffer stirng should be %s", expectedString)
@@ -541,6 +755,15 @@ func TestElementWriteText(t *testing.T) {
 	if bf.String() != expectedString {
 		t.Errorf("Buffer stirng should be %s", expectedString)
 	}
+
+	// When the content ends with a trim marker.
+	e = &Element{Text: "  This is a text.\\", Type: TypeContent}
+	bf = bytes.Buffer{}
+	e.writeText(&bf)
+	expectedString = "  This is a text."
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s", expectedString)
+	}
 }
 
 func TestElementTextValue(t *testing.T) {
@@ -616,7 +839,7 @@ func TestElementHasAttributes(t *testing.T) {
 func TestElementWriteAttributes(t *testing.T) {
 	e := &Element{Attributes: map[string]string{"a": "b"}}
 	var bf bytes.Buffer
-	e.writeAttributes(&bf)
+	e.writeAttributes(&bf, newContext(nil))
 	expectedString := ` a="b"`
 	if bf.String() != expectedString {
 		t.Errorf("Return string should be %s", expectedString)
@@ -627,7 +850,7 @@ func TestElementWriteTextValue(t *testing.T) {
 	// When the element's tag is doctype.
 	e := &Element{Tag: "doctype", TextValues: []string{"a", "b"}}
 	var bf bytes.Buffer
-	e.writeTextValue(&bf)
+	e.writeTextValue(&bf, newContext(nil))
 	expectedString := ``
 	if bf.String() != expectedString {
 		t.Errorf("Return string should be %s", expectedString)
@@ -636,7 +859,7 @@ func TestElementWriteTextValue(t *testing.T) {
 	// When the element's tag is not doctype.
 	e = &Element{Tag: "div", TextValues: []string{"a", "b"}}
 	bf = bytes.Buffer{}
-	e.writeTextValue(&bf)
+	e.writeTextValue(&bf, newContext(nil))
 	expectedString = `a b`
 	if bf.String() != expectedString {
 		t.Errorf("Return string should be %s", expectedString)
@@ -647,7 +870,7 @@ func TestElementCloseTag(t *testing.T) {
 	// When the element's tag is doctype.
 	e := &Element{Tag: "doctype"}
 	var bf bytes.Buffer
-	e.writeCloseTag(&bf)
+	e.writeCloseTag(&bf, newContext(nil))
 	expectedString := ``
 	if bf.String() != expectedString {
 		t.Errorf("Return string should be %s", expectedString)
@@ -656,7 +879,7 @@ func TestElementCloseTag(t *testing.T) {
 	// When the element's tag is not doctype.
 	e = &Element{Tag: "div"}
 	bf = bytes.Buffer{}
-	e.writeCloseTag(&bf)
+	e.writeCloseTag(&bf, newContext(nil))
 	expectedString = `</div>`
 	if bf.String() != expectedString {
 		t.Errorf("Return string should be %s", expectedString)
@@ -724,6 +947,20 @@ func TestElementSetType(t *testing.T) {
 		t.Errorf("Type should be %s", TypeOutputExpression)
 	}
 
+	// When the element's first token starts with a colon.
+	e = &Element{Tokens: []string{":markdown"}}
+	e.setType()
+	if e.Type != TypeFilter {
+		t.Errorf("Type should be %s", TypeFilter)
+	}
+
+	// When the element's first token is each.
+	e = &Element{Tokens: []string{"each", "item", "in", ".Items"}}
+	e.setType()
+	if e.Type != TypeEach {
+		t.Errorf("Type should be %s", TypeEach)
+	}
+
 }
 
 func TestElementGetTemplate(t *testing.T) {
@@ -767,12 +1004,93 @@ func TestElementLiteralValue(t *testing.T) {
 func TestElementWriteLiteralValue(t *testing.T) {
 	e := &Element{Tokens: []string{"|", "a", "b"}}
 	var bf bytes.Buffer
-	e.writeLiteralValue(&bf)
+	e.writeLiteralValue(&bf, newContext(nil))
 	if bf.String() != "a b" {
 		t.Errorf("Return string is invalid.")
 	}
 }
 
+func TestElementParseLexemes(t *testing.T) {
+	tpl := NewTemplate("", NewGenerator(false))
+	// When the text has double spaces and an equal sign.
+	e, err := NewElement("  p z=1 a=2 [b] 1+1=2  is  true", 1, 1, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if e.Col != 3 || len(e.TextValues) != 1 || e.TextValues[0] != "1+1=2  is  true" {
+		t.Errorf("The element is invalid. [col: %d][text values: %q]", e.Col, e.TextValues)
+	}
+	var bf bytes.Buffer
+	e.writeAttributes(&bf, newContext(nil))
+	if expectedString := ` z="1" a="2"`; bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s. [actual: %s]", expectedString, bf.String())
+	}
+
+	// When the text starts explicitly.
+	e, err = NewElement("p | a=b", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if len(e.Attributes) != 0 || e.textValue() != "a=b" {
+		t.Errorf("The element is invalid. [attributes: %v][text values: %q]", e.Attributes, e.TextValues)
+	}
+
+	// When the element has multiple ids.
+	_, err = NewElement("p#a id=b", 1, 0, nil, tpl, nil)
+	expectedErrMsg := "The number of the element id has to be one. (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the element is nested by the block expansion.
+	e, err = NewElement("li: a href=/ Home", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if e.Nested.Col != 5 || e.Nested.Lexemes[0].Col != 5 {
+		t.Errorf("The nested element's column should be %d. [actual: %d]", 5, e.Nested.Col)
+	}
+
+	// When the literal has double spaces.
+	e, err = NewElement("|  a  b", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if e.literalValue() != " a  b" {
+		t.Errorf("The literal value is invalid. [actual: %q]", e.literalValue())
+	}
+}
+
+func TestElementHtmlEscape(t *testing.T) {
+	g := NewGenerator(false)
+	src := `
+p title="say \"hi\"" Tom & Jerry <3 &amp; {{if lt 1 2}}x{{end}}
+a href='/a?b=1&c=2' z
+| a < b
+p! <b>raw</b>
+! <i>raw</i>
+`
+	_, html, err := g.ParseStringWithHTML(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<p title="say &#34;hi&#34;">Tom &amp; Jerry &lt;3 &amp; {{if lt 1 2}}x{{end}}</p><a href="/a?b=1&amp;c=2">z</a>a &lt; b<p><b>raw</b></p><i>raw</i>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the backend is text.
+	tpl, err := g.CompileStringBackend(TextBackend, map[string]string{"src": "| a < b & c"}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	tpl.Execute(&bf, nil)
+	if expectedString := "a < b & c\n"; bf.String() != expectedString {
+		t.Errorf("Text should be %q. [actual: %q]", expectedString, bf.String())
+	}
+}
+
 func TestElementComment(t *testing.T) {
 	// When the element is a comment.
 	e := &Element{Text: "//aaa"}
@@ -807,55 +1125,122 @@ func TestNewElement(t *testing.T) {
 	}
 }
 
-func TestTokens(t *testing.T) {
-	// When a pair of double quotes exists.
-	text := `div attr="val1 val2" AAA`
-	tkns := tokens(text)
-	if len(tkns) != 3 || tkns[0] != "div" || tkns[1] != `attr="val1 val2"` || tkns[2] != "AAA" {
-		t.Errorf("Returned value is invalid.")
+func TestNewElementBlockExpansion(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	e, err := NewElement("ul.nav: li: a href=/ Home", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
 	}
-
-	// When a double quote exists.
-	text = `div "AAA BBB`
-	tkns = tokens(text)
-	if len(tkns) != 3 || tkns[0] != "div" || tkns[1] != `"AAA` || tkns[2] != `BBB` {
+	inner := e.innermost()
+	if e.Tag != "ul" || e.Nested == nil || e.Nested.Tag != "li" || inner.Tag != "a" || inner.Indent != 0 {
 		t.Errorf("Returned value is invalid.")
 	}
+	var bf bytes.Buffer
+	if err := e.Html(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<ul class="nav"><li><a href="/">Home</a></li></ul>`
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s", expectedString)
+	}
 }
 
-func TestUnclosed(t *testing.T) {
-	// When the token is unclosed.
-	if unclosed, _ := unclosed(`aaa"bbb`); unclosed != true {
-		t.Errorf("Returned value should be true.")
+func TestElementAppendInlineChildren(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	// When the text has inline tags and output expressions.
+	e, err := NewElement(`p Hello #[a.user href=/users #{.Name}]! \#{x}`, 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
 	}
-
-	// When the token is closed.
-	if unclosed, _ := unclosed(`aaa"bbb"`); unclosed != false {
-		t.Errorf("Returned value should be false.")
+	if e.hasTextValues() || len(e.Children) != 3 || !e.Children[1].Inline {
+		t.Errorf("The element's children are invalid.")
+	}
+	var bf bytes.Buffer
+	if err := e.Html(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<p>Hello <a class="user" href="/users">{{.Name}}</a>! #{x}</p>`
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s [actual: %s]", expectedString, bf.String())
 	}
-}
 
-func TestClosed(t *testing.T) {
-	// When the token is closed.
-	if closed(`aaabbb"`, `"`) != true {
-		t.Errorf("Returned value should be true.")
+	// When the literal has an inline tag.
+	e, err = NewElement("| Hello #[strong Gold]", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	bf = bytes.Buffer{}
+	if err := e.Html(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `Hello <strong>Gold</strong>`
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s [actual: %s]", expectedString, bf.String())
 	}
 
-	// When the token is unclosed.
-	if closed("aaa", `"`) != false {
-		t.Errorf("Returned value should be false.")
+	// When the inline tag is not closed.
+	_, err = NewElement("p #[strong Gold", 1, 0, nil, tpl, nil)
+	expectedErrMsg := "The inline interpolation is not closed. (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
 	}
 }
 
-func TestAttribute(t *testing.T) {
-	// When the token is an attribute.
-	if attribute("a=b") != true {
-		t.Errorf("Returned value should be true.")
+func TestElementHtmlWhitespace(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	parent, err := NewElement("p", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	for i, line := range []string{"a> x", "a<> y", "a.link< z", "' and"} {
+		e, err := NewElement(line, i+2, 1, parent, nil, nil)
+		if err != nil {
+			t.Errorf("An error(%s) occurred.", err.Error())
+		}
+		parent.AppendChild(e)
+	}
+	if c := parent.Children[2]; c.Tag != "a" || len(c.Classes) != 1 || c.Classes[0] != "link" || !c.SpaceBefore || c.SpaceAfter {
+		t.Errorf("The element's whitespace operators are invalid.")
+	}
+	var bf bytes.Buffer
+	if err := parent.Html(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<p><a>x</a>  <a>y</a>  <a class="link">z</a>and </p>`
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s [actual: %s]", expectedString, bf.String())
 	}
+}
 
-	// When the token is not an attribute.
-	if attribute("abc") != false {
-		t.Errorf("Returned value should be false.")
+func TestElementHtmlPreformatted(t *testing.T) {
+	g := NewGenerator(false)
+	tpl := NewTemplate("/", g)
+	parent, err := NewElement("div", 1, 0, nil, tpl, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	e, err := NewElement("  pre.", 2, 1, parent, nil, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	parent.AppendChild(e)
+	for i, line := range []string{"    func main() {", "    \tfmt.Println()", "    }"} {
+		child, err := NewElement(line, i+3, indent(line), e, nil, nil)
+		if err != nil {
+			t.Errorf("An error(%s) occurred.", err.Error())
+		}
+		e.AppendChild(child)
+	}
+	var bf bytes.Buffer
+	if err := parent.Html(&bf, nil); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<div><pre>func main() {\n\tfmt.Println()\n}</pre></div>"
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s [actual: %s]", expectedString, bf.String())
 	}
 }
 
@@ -865,6 +1250,16 @@ func TestParseValue(t *testing.T) {
 		t.Errorf("Returned value is invalid.")
 	}
 
+	// When the value is enclosed by single quotes.
+	if parseValue(`'a "b"'`) != `a "b"` {
+		t.Errorf("Returned value is invalid.")
+	}
+
+	// When the value has escaped quotes.
+	if parseValue(`"a \"b\""`) != `a "b"` {
+		t.Errorf("Returned value is invalid.")
+	}
+
 	// When the value is not literal.
 	if parseValue("aaa") != "aaa" {
 		t.Errorf("Returned value is invalid.")
@@ -894,3 +1289,64 @@ func TestExpression(t *testing.T) {
 		t.Errorf("Returned value should be false.")
 	}
 }
+
+func TestAppendElement(t *testing.T) {
+	head := &Element{Type: TypeRange, Tokens: []string{"range", ".Items"}}
+	elseIf := &Element{Type: TypeElse, Tokens: []string{"else", "if", ".X"}}
+	els := &Element{Type: TypeElse, Tokens: []string{"else"}}
+	// When an else if element follows a range element.
+	elements := appendElement(nil, head)
+	elements = appendElement(elements, elseIf)
+	if head.Else != nil || elseIf.Head != nil {
+		t.Errorf("The else if element should not be chained.")
+	}
+
+	// When an else element follows a range element.
+	elements = appendElement(elements[:1], els)
+	if head.Else != els || els.Head != head || len(elements) != 2 {
+		t.Errorf("The else element should be chained.")
+	}
+}
+
+func TestSplitValues(t *testing.T) {
+	values := splitValues(` "a, b", 'c', d `)
+	if len(values) != 3 || values[0] != `"a, b"` || values[1] != "'c'" || values[2] != "d" {
+		t.Errorf("Returned value is invalid.")
+	}
+}
+
+func TestBlockExpansion(t *testing.T) {
+	// When the first token ends with a colon.
+	if blockExpansion([]string{"li:", "a"}) != true {
+		t.Errorf("Returned value should be true.")
+	}
+
+	// When the first token is "javascript:".
+	if blockExpansion([]string{"javascript:", "a"}) != false {
+		t.Errorf("Returned value should be false.")
+	}
+}
+
+func TestClosingBracket(t *testing.T) {
+	// When the bracket is closed.
+	if closingBracket("#[a #[b]]c", 1) != 8 {
+		t.Errorf("Returned value is invalid.")
+	}
+
+	// When the bracket is not closed.
+	if closingBracket("#{a", 1) != -1 {
+		t.Errorf("Returned value is invalid.")
+	}
+}
+
+func TestDedent(t *testing.T) {
+	// When the string has more indents than the number.
+	if dedent("\t    a", 2) != "  a" {
+		t.Errorf("Returned value is invalid.")
+	}
+
+	// When the string has less indents than the number.
+	if dedent("  a", 2) != "a" {
+		t.Errorf("Returned value is invalid.")
+	}
+}
@@ -8,20 +8,28 @@ import (
 // An EmbedMap represents a map for embedding strings to Gold tempaltes.
 type EmbedMap map[string]string
 
-// A NewEmbedMap generates an EmbedMap and returns it.
+// A NewEmbedMap generates an EmbedMap and returns it. A parameter is split
+// on its first = so that a value can have =. A value can be enclosed by
+// double quotes or single quotes.
 func NewEmbedMap(kvs []string) (EmbedMap, error) {
 	embedMap := EmbedMap{}
 	for _, kv := range kvs {
-		kvMap := strings.Split(kv, "=")
-		if len(kvMap) != 2 {
+		k, v, ok := splitParameter(kv)
+		if !ok {
 			return nil, fmt.Errorf("the parameter did not have = and a key-value could not be derived. [parameter: %s]", kv)
 		}
-		embedMap[trimDoubleQuote(kvMap[0])] = trimDoubleQuote(kvMap[1])
+		embedMap[k] = v
 	}
 	return embedMap, nil
 }
 
-// trimDoubleQuote trims double quotes.
-func trimDoubleQuote(s string) string {
-	return strings.TrimSuffix(strings.TrimPrefix(s, `"`), `"`)
+// splitParameter splits the parameter (key=value) on its first = and
+// returns the key and the unquoted value. ok is false if the parameter does
+// not have =.
+func splitParameter(kv string) (k string, v string, ok bool) {
+	i := strings.Index(kv, "=")
+	if i < 0 {
+		return "", "", false
+	}
+	return parseValue(kv[:i]), parseValue(kv[i+1:]), true
 }
@@ -1,6 +1,7 @@
 package gold
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -11,6 +12,16 @@ func TestNewEmbedMap(t *testing.T) {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 
+	// When the values are quoted or have =.
+	embedMap, err := NewEmbedMap([]string{`a=x=y`, `b="c d"`, `c='it\'s'`, `d="say \"hi\""`, `e=`})
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expected := EmbedMap{"a": "x=y", "b": "c d", "c": "it's", "d": `say "hi"`, "e": ""}
+	if !reflect.DeepEqual(embedMap, expected) {
+		t.Errorf("EmbedMap should be %v. [actual: %v]", expected, embedMap)
+	}
+
 	// When an error occurs.
 	_, err = NewEmbedMap([]string{"name"})
 	expectedErrMsg := "the parameter did not have = and a key-value could not be derived. [parameter: name]"
@@ -0,0 +1,84 @@
+package gold
+
+import (
+	"fmt"
+	"sort"
+)
+
+// An Error represents an error of a Gold template at a line.
+type Error struct {
+	Template string
+	LineNo   int
+	Err      error
+}
+
+// Error returns the error's message.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// An ErrorList is a list of the errors of Gold templates. The errors are
+// sorted by their positions.
+type ErrorList []*Error
+
+// Len returns the number of the errors.
+func (p ErrorList) Len() int {
+	return len(p)
+}
+
+// Swap swaps the errors.
+func (p ErrorList) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+// Less returns if the error i is located before the error j or not.
+func (p ErrorList) Less(i, j int) bool {
+	if p[i].Template != p[j].Template {
+		return p[i].Template < p[j].Template
+	}
+	return p[i].LineNo < p[j].LineNo
+}
+
+// Sort sorts the errors by their positions.
+func (p ErrorList) Sort() {
+	sort.Stable(p)
+}
+
+// Error returns the first error's message and the number of the other
+// errors. The message of a list which has one error is equal to the error's
+// message.
+func (p ErrorList) Error() string {
+	switch len(p) {
+	case 0:
+		return "no errors"
+	case 1:
+		return p[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", p[0].Error(), len(p)-1)
+}
+
+// Err returns the sorted list or nil if the list has no errors.
+func (p ErrorList) Err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	p.Sort()
+	return p
+}
+
+// add appends the error which occurred at the template's line to the list.
+// The errors of an ErrorList are appended as they are.
+func (p *ErrorList) add(tpl *Template, lineNo int, err error) {
+	switch err := err.(type) {
+	case ErrorList:
+		*p = append(*p, err...)
+	case *Error:
+		*p = append(*p, err)
+	default:
+		e := &Error{LineNo: lineNo, Err: err}
+		if tpl != nil {
+			e.Template = tpl.Path
+		}
+		*p = append(*p, e)
+	}
+}
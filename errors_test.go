@@ -0,0 +1,39 @@
+package gold
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorList(t *testing.T) {
+	var errs ErrorList
+	if errs.Err() != nil {
+		t.Errorf("Err should return nil.")
+	}
+	if errs.Error() != "no errors" {
+		t.Errorf("Error should be %s. [actual: %s]", "no errors", errs.Error())
+	}
+	errs.add(&Template{Path: "b"}, 1, errors.New("error b1"))
+	errs.add(nil, 0, &Error{Template: "a", LineNo: 5, Err: errors.New("error a5")})
+	if errs.Error() != "error b1 (and 1 more errors)" {
+		t.Errorf("Error should be %s. [actual: %s]", "error b1 (and 1 more errors)", errs.Error())
+	}
+	errs.add(nil, 0, ErrorList{{Template: "a", LineNo: 2, Err: errors.New("error a2")}})
+	err := errs.Err()
+	if err == nil {
+		t.Errorf("Err should return the list.")
+		return
+	}
+	expected := []string{"error a2", "error a5", "error b1"}
+	for i, e := range err.(ErrorList) {
+		if e.Error() != expected[i] {
+			t.Errorf("Error %d should be %s. [actual: %s]", i, expected[i], e.Error())
+		}
+	}
+
+	// When the list has one error.
+	errs = ErrorList{{Template: "a", LineNo: 1, Err: errors.New("error")}}
+	if errs.Error() != "error" {
+		t.Errorf("Error should be %s. [actual: %s]", "error", errs.Error())
+	}
+}
@@ -0,0 +1,53 @@
+package gold
+
+import (
+	"regexp"
+	"strings"
+)
+
+// entity matches the character references.
+var entity = regexp.MustCompile(`^&(?:[a-zA-Z][a-zA-Z0-9]*|#[0-9]+|#[xX][0-9a-fA-F]+);`)
+
+// escapeText escapes <, > and the ampersands which do not start character
+// references in the parts of the string which are not enclosed by the
+// delimiters.
+func escapeText(s, delimLeft, delimRight string) string {
+	return mapText(s, delimLeft, delimRight, func(t string) string {
+		return strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(escapeAmpersands(t))
+	})
+}
+
+// escapeAttribute escapes double quotes and the ampersands which do not
+// start character references in the parts of the string which are not
+// enclosed by the delimiters.
+func escapeAttribute(s, delimLeft, delimRight string) string {
+	return mapText(s, delimLeft, delimRight, func(t string) string {
+		return strings.Replace(escapeAmpersands(t), `"`, "&#34;", -1)
+	})
+}
+
+// escapeAmpersands escapes the ampersands which do not start character
+// references.
+func escapeAmpersands(s string) string {
+	if !strings.Contains(s, "&") {
+		return s
+	}
+	parts := strings.Split(s, "&")
+	for i := 1; i < len(parts); i++ {
+		if !entity.MatchString("&" + parts[i]) {
+			parts[i] = "amp;" + parts[i]
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// unquote removes the quotes which enclose the value and unescapes the
+// escaped quotes and backslashes in it.
+func unquote(value string) string {
+	q := value[0]
+	value = value[1 : len(value)-1]
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	return strings.NewReplacer(`\\`, `\`, `\`+string(q), string(q)).Replace(value)
+}
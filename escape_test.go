@@ -0,0 +1,49 @@
+package gold
+
+import (
+	"testing"
+)
+
+func TestEscapeText(t *testing.T) {
+	expectedString := `a &lt;b&gt; &amp; &amp;c &#38; {{if lt .A "<"}}d{{end}}`
+	if s := escapeText(`a <b> & &amp;c &#38; {{if lt .A "<"}}d{{end}}`, "{{", "}}"); s != expectedString {
+		t.Errorf("Return string should be %s. [actual: %s]", expectedString, s)
+	}
+}
+
+func TestEscapeAttribute(t *testing.T) {
+	expectedString := `say &#34;hi&#34; &amp; &lt; {{printf "%s" .A}}`
+	if s := escapeAttribute(`say "hi" & &lt; {{printf "%s" .A}}`, "{{", "}}"); s != expectedString {
+		t.Errorf("Return string should be %s. [actual: %s]", expectedString, s)
+	}
+}
+
+func TestEscapeAmpersands(t *testing.T) {
+	cases := map[string]string{
+		"a":           "a",
+		"a & b":       "a &amp; b",
+		"&amp;&#38;":  "&amp;&#38;",
+		"&#x26;&x;&;": "&#x26;&x;&amp;;",
+		"AT&T &copy":  "AT&amp;T &amp;copy",
+	}
+	for s, expectedString := range cases {
+		if actual := escapeAmpersands(s); actual != expectedString {
+			t.Errorf("Return string should be %s. [actual: %s]", expectedString, actual)
+		}
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	cases := map[string]string{
+		`"a b"`:        "a b",
+		`"say \"hi\""`: `say "hi"`,
+		`'it\'s'`:      "it's",
+		`'a\\b'`:       `a\b`,
+		`'say "hi"'`:   `say "hi"`,
+	}
+	for s, expectedString := range cases {
+		if actual := unquote(s); actual != expectedString {
+			t.Errorf("Return string should be %s. [actual: %s]", expectedString, actual)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package gold
+
+import (
+	"html"
+)
+
+// A Filter converts the text beneath a filter element.
+type Filter func([]byte) ([]byte, error)
+
+// filters holds the built-in filters.
+var filters = map[string]Filter{
+	"css":   cssFilter,
+	"cdata": cdataFilter,
+}
+
+// delimFilters holds the built-in filters which keep the actions enclosed by
+// the generator's delimiters intact.
+var delimFilters = map[string]func(delimLeft, delimRight string) Filter{
+	"markdown": markdownFilter,
+	"escaped":  escapedFilter,
+}
+
+// cssFilter encloses the text with a style tag.
+func cssFilter(b []byte) ([]byte, error) {
+	return []byte("<style>" + string(b) + "</style>"), nil
+}
+
+// cdataFilter encloses the text with a CDATA section.
+func cdataFilter(b []byte) ([]byte, error) {
+	return []byte("<![CDATA[" + string(b) + "]]>"), nil
+}
+
+// escapedFilter returns a filter which escapes the text. Actions enclosed
+// by the delimiters are not escaped.
+func escapedFilter(delimLeft, delimRight string) Filter {
+	return func(b []byte) ([]byte, error) {
+		return []byte(mapText(string(b), delimLeft, delimRight, html.EscapeString)), nil
+	}
+}
@@ -0,0 +1,44 @@
+package gold
+
+import (
+	"testing"
+)
+
+func TestCssFilter(t *testing.T) {
+	b, err := cssFilter([]byte("a {}"))
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if string(b) != "<style>a {}</style>" {
+		t.Errorf("Returned value is invalid.")
+	}
+}
+
+func TestCdataFilter(t *testing.T) {
+	b, err := cdataFilter([]byte("a < b"))
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if string(b) != "<![CDATA[a < b]]>" {
+		t.Errorf("Returned value is invalid.")
+	}
+}
+
+func TestEscapedFilter(t *testing.T) {
+	b, err := escapedFilter("{{", "}}")([]byte(`<a href="/">`))
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if string(b) != "&lt;a href=&#34;/&#34;&gt;" {
+		t.Errorf("Returned value is invalid. [actual: %s]", string(b))
+	}
+
+	// When the text has actions.
+	b, err = escapedFilter("<%", "%>")([]byte(`<b><%printf "%s" .X%></b>`))
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if string(b) != `&lt;b&gt;<%printf "%s" .X%>&lt;/b&gt;` {
+		t.Errorf("Returned value is invalid. [actual: %s]", string(b))
+	}
+}
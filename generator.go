@@ -6,7 +6,11 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	texttemplate "text/template"
 
 	"github.com/yosssi/gohtml"
 )
@@ -21,22 +25,48 @@ const (
 	defaultDelimRight     = "}}"
 )
 
-// Generator represents an HTML generator.
+// preformattedElements holds the regular expressions which match the elements
+// whose whitespaces are significant.
+var preformattedElements = []*regexp.Regexp{
+	regexp.MustCompile(`(?is)<pre\b.*?</pre>`),
+	regexp.MustCompile(`(?is)<textarea\b.*?</textarea>`),
+	regexp.MustCompile(`(?is)<code\b.*?</code>`),
+}
+
+// Generator represents an HTML generator. A generator can be used by
+// multiple goroutines simultaneously.
 type Generator struct {
-	cache        bool
-	templates    map[string]*template.Template
-	htmls        map[string]string
-	gtemplates   map[string]*Template
-	helperFuncs  template.FuncMap
-	baseDir      string
-	prettyPrint  bool
-	debugWriter  io.Writer
-	asset        func(string) ([]byte, error)
-	assetBaseDir string
-	delimLeft    string
-	delimRight   string
+	cache            bool
+	mu               sync.RWMutex
+	templates        map[string]*template.Template
+	templateSets     map[string]*template.Template
+	textTemplates    map[Backend]map[string]*texttemplate.Template
+	backend          Backend
+	htmls            map[string]string
+	gtemplates       map[string]*Template
+	helperFuncs      template.FuncMap
+	baseDir          string
+	prettyPrint      bool
+	minify           bool
+	minifyRawContent bool
+	debugWriter      io.Writer
+	asset            func(string) ([]byte, error)
+	assetBaseDir     string
+	delimLeft        string
+	delimRight       string
+	filters          map[string]Filter
+	doctypes         map[string]string
+	transforms       []Transform
+	preprocessors    []Preprocessor
 }
 
+// A Transform modifies a parsed Gold template before HTML is generated.
+type Transform func(*Template) error
+
+// A Preprocessor modifies the source codes of a Gold template before they
+// are parsed.
+type Preprocessor func(path, src string) (string, error)
+
 // ParseFile parses a Gold template file and returns an HTML template.
 func (g *Generator) ParseFile(path string) (*template.Template, error) {
 	tpl, _, err := g.generateTemplate(path, nil, true)
@@ -67,6 +97,22 @@ func (g *Generator) SetPrettyPrint(prettyPrint bool) *Generator {
 	return g
 }
 
+// SetMinify sets the minify to the generator. The generator strips
+// insignificant whitespaces and optional quotes from HTML except for the
+// contents of pre and textarea elements and actions.
+func (g *Generator) SetMinify(minify bool) *Generator {
+	g.minify = minify
+	return g
+}
+
+// SetMinifyRawContent sets the minifyRawContent to the generator. The
+// generator minifies the contents of script and style elements too if it
+// minifies HTML.
+func (g *Generator) SetMinifyRawContent(minifyRawContent bool) *Generator {
+	g.minifyRawContent = minifyRawContent
+	return g
+}
+
 // SetDebugWriter sets a debugWriter to the generator.
 func (g *Generator) SetDebugWriter(debugWriter io.Writer) *Generator {
 	g.debugWriter = debugWriter
@@ -86,6 +132,56 @@ func (g *Generator) Delims(left, right string) *Generator {
 	return g
 }
 
+// RegisterFilter registers the filter to the generator. A filter element
+// (:name) converts the text beneath it by the filter while parsing.
+func (g *Generator) RegisterFilter(name string, filter Filter) *Generator {
+	if g.filters == nil {
+		g.filters = make(map[string]Filter)
+	}
+	g.filters[name] = filter
+	return g
+}
+
+// RegisterDoctype registers the doctype declaration to the generator. A
+// doctype element (doctype name) writes the declaration. The declarations
+// based on XML switch the output to XHTML.
+func (g *Generator) RegisterDoctype(name, decl string) *Generator {
+	if g.doctypes == nil {
+		g.doctypes = make(map[string]string)
+	}
+	g.doctypes[name] = decl
+	return g
+}
+
+// AddTransform adds the transform to the generator. The transforms are
+// applied in the order in which they are added to each parsed Gold template
+// including super templates and included templates. The transformed
+// templates are cached if the generator caches templates.
+func (g *Generator) AddTransform(transform Transform) *Generator {
+	g.transforms = append(g.transforms, transform)
+	return g
+}
+
+// AddPreprocessor adds the preprocessor to the generator. The preprocessors
+// are applied in the order in which they are added to the source codes of
+// each Gold template before they are parsed.
+func (g *Generator) AddPreprocessor(preprocessor Preprocessor) *Generator {
+	g.preprocessors = append(g.preprocessors, preprocessor)
+	return g
+}
+
+// filter returns the filter which is registered to the generator or is
+// built in.
+func (g *Generator) filter(name string) Filter {
+	if filter, prs := g.filters[name]; prs {
+		return filter
+	}
+	if filter, prs := delimFilters[name]; prs {
+		return filter(g.delimLeft, g.delimRight)
+	}
+	return filters[name]
+}
+
 // ParseString parses a Gold template string and returns an HTML template.
 func (g *Generator) ParseString(stringTemplates map[string]string, name string) (*template.Template, error) {
 	tpl, _, err := g.generateTemplate(name, stringTemplates, false)
@@ -97,43 +193,325 @@ func (g *Generator) ParseStringWithHTML(stringTemplates map[string]string, name
 	return g.generateTemplate(name, stringTemplates, false)
 }
 
+// ParseXMLFile parses a Gold template file and returns a text template which
+// generates an XML document. Empty elements are closed and the outputs of
+// the actions are escaped for XML.
+func (g *Generator) ParseXMLFile(path string) (*texttemplate.Template, error) {
+	return g.generateTextTemplate(path, nil, true, XMLBackend)
+}
+
+// ParseXMLString parses a Gold template string and returns a text template
+// which generates an XML document.
+func (g *Generator) ParseXMLString(stringTemplates map[string]string, name string) (*texttemplate.Template, error) {
+	return g.generateTextTemplate(name, stringTemplates, false, XMLBackend)
+}
+
+// SetBackend sets the backend to the generator. The backend decides the
+// template package which Compile and CompileString use.
+func (g *Generator) SetBackend(backend Backend) *Generator {
+	g.backend = backend
+	return g
+}
+
+// Compile parses a Gold template file and returns a template of the
+// generator's backend.
+func (g *Generator) Compile(path string) (Executor, error) {
+	return g.compile(path, nil, true, g.backend)
+}
+
+// CompileString parses a Gold template string and returns a template of the
+// generator's backend.
+func (g *Generator) CompileString(stringTemplates map[string]string, name string) (Executor, error) {
+	return g.compile(name, stringTemplates, false, g.backend)
+}
+
+// CompileBackend parses a Gold template file and returns a template of the
+// backend regardless of the generator's backend.
+func (g *Generator) CompileBackend(backend Backend, path string) (Executor, error) {
+	return g.compile(path, nil, true, backend)
+}
+
+// CompileStringBackend parses a Gold template string and returns a template
+// of the backend regardless of the generator's backend.
+func (g *Generator) CompileStringBackend(backend Backend, stringTemplates map[string]string, name string) (Executor, error) {
+	return g.compile(name, stringTemplates, false, backend)
+}
+
+// compile parses a Gold template and returns a template of the backend.
+func (g *Generator) compile(path string, stringTemplates map[string]string, addBaseDir bool, backend Backend) (Executor, error) {
+	if backend != HTMLBackend {
+		tpl, err := g.generateTextTemplate(path, stringTemplates, addBaseDir, backend)
+		if err != nil {
+			return nil, err
+		}
+		return tpl, nil
+	}
+	tpl, _, err := g.generateTemplate(path, stringTemplates, addBaseDir)
+	if err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// ParseTree parses a Gold template file and returns the parsed tree without
+// generating HTML. The tree can be traversed by Walk and Inspect. If the
+// template has errors, the partial tree and an ErrorList which holds all
+// the errors are returned.
+func (g *Generator) ParseTree(path string) (*Template, error) {
+	return g.parse(path, nil, true)
+}
+
+// ParseTreeString parses a Gold template string and returns the parsed tree
+// without generating HTML. If the template has errors, the partial tree and
+// an ErrorList which holds all the errors are returned.
+func (g *Generator) ParseTreeString(stringTemplates map[string]string, name string) (*Template, error) {
+	return g.parse(name, stringTemplates, false)
+}
+
+// ParseGlob parses the Gold template files matched by the pattern and returns
+// an HTML template set. Each file is associated with the set under its path
+// relative to the pattern's base directory without the extension so that
+// the files can call each other by the {{template}} action.
+func (g *Generator) ParseGlob(pattern string) (*template.Template, error) {
+	pattern = Path(g.baseDir, pattern)
+	if g.cache {
+		g.mu.RLock()
+		tpl, prs := g.templateSets[pattern]
+		g.mu.RUnlock()
+		if prs {
+			return tpl, nil
+		}
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("the pattern matched no files. [pattern: %s]", pattern)
+	}
+	tpl, err := g.generateTemplateSet(globRoot(pattern), paths)
+	if err != nil {
+		return nil, err
+	}
+	if g.cache {
+		g.mu.Lock()
+		g.templateSets[pattern] = tpl
+		g.mu.Unlock()
+	}
+	return tpl, nil
+}
+
+// ParseDir parses the Gold template files under the directory recursively
+// and returns an HTML template set. Each file is associated with the set
+// under its path relative to the directory without the extension.
+func (g *Generator) ParseDir(dir string) (*template.Template, error) {
+	dir = Path(g.baseDir, dir)
+	if g.cache {
+		g.mu.RLock()
+		tpl, prs := g.templateSets[dir]
+		g.mu.RUnlock()
+		if prs {
+			return tpl, nil
+		}
+	}
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, Extension) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("the directory has no Gold template files. [directory: %s]", dir)
+	}
+	tpl, err := g.generateTemplateSet(dir, paths)
+	if err != nil {
+		return nil, err
+	}
+	if g.cache {
+		g.mu.Lock()
+		g.templateSets[dir] = tpl
+		g.mu.Unlock()
+	}
+	return tpl, nil
+}
+
 // generateTemplate parses a Gold template and returns an HTML template.
 func (g *Generator) generateTemplate(path string, stringTemplates map[string]string, addBaseDir bool) (*template.Template, string, error) {
 	if g.cache {
-		if tpl, prs := g.templates[path]; prs {
-			html := g.htmls[path]
+		g.mu.RLock()
+		tpl, prs := g.templates[path]
+		html := g.htmls[path]
+		g.mu.RUnlock()
+		if prs {
 			return tpl, html, nil
 		}
 	}
-	gtpl, err := g.parse(path, stringTemplates, addBaseDir)
+	gtpl, html, err := g.generateHTML(path, addBaseDir, newContext(stringTemplates))
 	if err != nil {
 		return nil, "", err
 	}
-	html, err := gtpl.Html(stringTemplates, nil)
+	tpl := g.newTemplate(path)
+	_, err = tpl.Parse(html)
+	if err != nil {
+		return nil, html, err
+	}
+	if err := checkTemplateCalls(defined(tpl), stringTemplates, gtpl); err != nil {
+		return nil, html, err
+	}
+	if g.cache {
+		g.mu.Lock()
+		g.templates[path] = tpl
+		g.htmls[path] = html
+		g.mu.Unlock()
+	}
+	return tpl, html, nil
+}
+
+// generateTextTemplate parses a Gold template and returns a text template
+// of the backend.
+func (g *Generator) generateTextTemplate(path string, stringTemplates map[string]string, addBaseDir bool, backend Backend) (*texttemplate.Template, error) {
+	if g.cache {
+		g.mu.RLock()
+		tpl, prs := g.textTemplates[backend][path]
+		g.mu.RUnlock()
+		if prs {
+			return tpl, nil
+		}
+	}
+	ctx := newContext(stringTemplates)
+	ctx.text = true
+	ctx.xml = backend == XMLBackend
+	ctx.plain = backend == PlainTextBackend
+	gtpl, src, err := g.generateHTML(path, addBaseDir, ctx)
+	if err != nil {
+		return nil, err
+	}
+	tpl := g.newTextTemplate(path)
+	if ctx.xml {
+		tpl.Funcs(xmlFuncs)
+	}
+	if _, err := tpl.Parse(src); err != nil {
+		return nil, err
+	}
+	if err := checkTemplateCalls(func(name string) bool { return tpl.Lookup(name) != nil }, stringTemplates, gtpl); err != nil {
+		return nil, err
+	}
+	if g.cache {
+		g.mu.Lock()
+		if g.textTemplates[backend] == nil {
+			g.textTemplates[backend] = make(map[string]*texttemplate.Template)
+		}
+		g.textTemplates[backend][path] = tpl
+		g.mu.Unlock()
+	}
+	return tpl, nil
+}
+
+// generateTemplateSet parses the Gold template files and returns an HTML
+// template set which is named after the first file.
+func (g *Generator) generateTemplateSet(root string, paths []string) (*template.Template, error) {
+	var set *template.Template
+	var gtpls []*Template
+	for _, path := range paths {
+		gtpl, html, err := g.generateHTML(path, false, newContext(nil))
+		if err != nil {
+			return nil, err
+		}
+		gtpls = append(gtpls, gtpl)
+		name := templateName(root, path)
+		var tpl *template.Template
+		if set == nil {
+			set = g.newTemplate(name)
+			tpl = set
+		} else {
+			tpl = set.New(name)
+		}
+		if _, err := tpl.Parse(html); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkTemplateCalls(defined(set), nil, gtpls...); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// defined returns a function which reports if the template is defined in the
+// HTML template set or not.
+func defined(set *template.Template) func(string) bool {
+	return func(name string) bool {
+		return set.Lookup(name) != nil
+	}
+}
+
+// checkTemplateCalls checks that the templates called by the template
+// elements of the Gold templates are defined in the template set.
+func checkTemplateCalls(defined func(string) bool, stringTemplates map[string]string, gtpls ...*Template) error {
+	for _, gtpl := range gtpls {
+		calls, err := gtpl.templateCalls(stringTemplates)
+		if err != nil {
+			return err
+		}
+		for _, e := range calls {
+			if !defined(e.templateName()) {
+				return fmt.Errorf("the template %s is not defined. [template: %s][lineno: %d][line: %s]", e.templateName(), e.getTemplate().Path, e.LineNo, e.Text)
+			}
+		}
+	}
+	return nil
+}
+
+// generateHTML parses a Gold template and returns the Gold template and
+// the source codes generated within the context.
+func (g *Generator) generateHTML(path string, addBaseDir bool, ctx *context) (*Template, string, error) {
+	gtpl, err := g.parse(path, ctx.stringTemplates, addBaseDir)
+	if err != nil {
+		return nil, "", err
+	}
+	html, err := gtpl.html(ctx, nil)
 	if err != nil {
 		return nil, "", err
 	}
-	if g.prettyPrint {
-		html = gohtml.Format(html)
+	if ctx.plain {
+		html += ctx.footnotes()
+	}
+	if g.prettyPrint && !g.minify && !ctx.text {
+		html = formatHTML(html)
 	}
 	if g.debugWriter != nil {
 		debugStr := gohtml.AddLineNo(html)
 		g.debugWriter.Write([]byte(debugStr + "\n"))
 	}
-	tpl := template.New(path)
+	return gtpl, html, nil
+}
+
+// newTemplate returns a new HTML template which has the generator's helper
+// functions and delimiters.
+func (g *Generator) newTemplate(name string) *template.Template {
+	tpl := template.New(name)
 	tpl.Funcs(g.helperFuncs)
 	if g.delimLeft != defaultDelimLeft || g.delimRight != defaultDelimRight {
 		tpl.Delims(g.delimLeft, g.delimRight)
 	}
-	_, err = tpl.Parse(html)
-	if err != nil {
-		return nil, html, err
-	}
-	if g.cache {
-		g.templates[path] = tpl
-		g.htmls[path] = html
+	return tpl
+}
+
+// newTextTemplate returns a new text template which has the generator's
+// helper functions and delimiters.
+func (g *Generator) newTextTemplate(name string) *texttemplate.Template {
+	tpl := texttemplate.New(name)
+	tpl.Funcs(texttemplate.FuncMap(g.helperFuncs))
+	if g.delimLeft != defaultDelimLeft || g.delimRight != defaultDelimRight {
+		tpl.Delims(g.delimLeft, g.delimRight)
 	}
-	return tpl, html, nil
+	return tpl
 }
 
 // parse parses a Gold template file and returns a Gold template.
@@ -142,7 +520,10 @@ func (g *Generator) parse(path string, stringTemplates map[string]string, addBas
 		path = Path(g.baseDir, path)
 	}
 	if g.cache {
-		if tpl, prs := g.gtemplates[path]; prs {
+		g.mu.RLock()
+		tpl, prs := g.gtemplates[path]
+		g.mu.RUnlock()
+		if prs {
 			return tpl, nil
 		}
 	}
@@ -162,9 +543,17 @@ func (g *Generator) parse(path string, stringTemplates map[string]string, addBas
 	} else {
 		s = stringTemplates[path]
 	}
+	for _, preprocessor := range g.preprocessors {
+		var err error
+		if s, err = preprocessor(path, s); err != nil {
+			return nil, fmt.Errorf("the preprocessor returned an error: %s [template: %s]", err.Error(), path)
+		}
+	}
 	lines := strings.Split(formatLf(s), "\n")
 	i, l := 0, len(lines)
 	tpl := NewTemplate(path, g)
+	tpl.stringTemplates = stringTemplates
+	var errs ErrorList
 	for i < l {
 		line := lines[i]
 		i++
@@ -176,7 +565,8 @@ func (g *Generator) parse(path string, stringTemplates map[string]string, addBas
 			case isExtends(line):
 				tokens := strings.Split(strings.TrimSpace(line), " ")
 				if l := len(tokens); l != extendsBlockTokensLen {
-					return nil, fmt.Errorf("the line tokens length is invalid. (expected: %d, actual: %d, line no: %d, template: %s, line: %s)", extendsBlockTokensLen, l, i, tpl.Path, strings.TrimSpace(line))
+					errs.add(tpl, i, fmt.Errorf("the line tokens length is invalid. (expected: %d, actual: %d, line no: %d, template: %s, line: %s)", extendsBlockTokensLen, l, i, tpl.Path, strings.TrimSpace(line)))
+					continue
 				}
 				superTplPath := tokens[1]
 				var superTpl *Template
@@ -192,34 +582,63 @@ func (g *Generator) parse(path string, stringTemplates map[string]string, addBas
 					superTpl, err = g.parse(superTplPath, stringTemplates, false)
 				}
 				if err != nil {
-					return nil, err
+					errs.add(tpl, i, err)
+				}
+				if superTpl == nil {
+					continue
 				}
-				superTpl.Sub = tpl
+				tpl.Extends = tokens[1]
 				tpl.Super = superTpl
+			case isParam(line):
+				p, err := newParam(line, i, tpl)
+				if err != nil {
+					errs.add(tpl, i, err)
+					continue
+				}
+				tpl.Params = append(tpl.Params, p)
 			case tpl.Super != nil && isBlock(line):
 				tokens := strings.Split(strings.TrimSpace(line), " ")
 				if l := len(tokens); l != extendsBlockTokensLen {
-					return nil, fmt.Errorf("the line tokens length is invalid. (expected: %d, actual: %d, line no: %d, template: %s, line: %s)", extendsBlockTokensLen, l, i, tpl.Path, strings.TrimSpace(line))
+					errs.add(tpl, i, fmt.Errorf("the line tokens length is invalid. (expected: %d, actual: %d, line no: %d, template: %s, line: %s)", extendsBlockTokensLen, l, i, tpl.Path, strings.TrimSpace(line)))
+					skipLines(lines, &i, &l, indentTop)
+					continue
 				}
-				block := &Block{Name: tokens[1], Template: tpl}
+				block := &Block{Name: tokens[1], Template: tpl, LineNo: i}
 				tpl.AddBlock(block.Name, block)
 				if err := appendChildren(block, lines, &i, &l, indentTop, false, "", tpl); err != nil {
-					return nil, err
+					errs.add(tpl, i, err)
 				}
 			default:
 				e, err := NewElement(line, i, indentTop, nil, tpl, nil)
 				if err != nil {
-					return nil, err
+					errs.add(tpl, i, err)
+					skipLines(lines, &i, &l, indentTop)
+					continue
 				}
 				tpl.AppendElement(e)
-				if err := appendChildren(e, lines, &i, &l, indentTop, e.RawContent, e.Type, tpl); err != nil {
-					return nil, err
+				inner := e.innermost()
+				if err := appendChildren(inner, lines, &i, &l, indentTop, inner.RawContent, inner.Type, tpl); err != nil {
+					errs.add(tpl, i, err)
+				}
+				if err := e.validate(); err != nil {
+					errs.add(tpl, e.LineNo, err)
 				}
 			}
 		}
 	}
+	tpl.resolveTemplateCalls()
+	if err := errs.Err(); err != nil {
+		return tpl, err
+	}
+	for _, transform := range g.transforms {
+		if err := transform(tpl); err != nil {
+			return nil, fmt.Errorf("the transform returned an error: %s [template: %s]", err.Error(), path)
+		}
+	}
 	if g.cache {
+		g.mu.Lock()
 		g.gtemplates[path] = tpl
+		g.mu.Unlock()
 	}
 	return tpl, nil
 }
@@ -230,7 +649,7 @@ func NewGenerator(cache bool) *Generator {
 	if err != nil {
 		baseDir = ""
 	}
-	return &Generator{cache: cache, templates: make(map[string]*template.Template), gtemplates: make(map[string]*Template), htmls: make(map[string]string), baseDir: baseDir, delimLeft: defaultDelimLeft, delimRight: defaultDelimRight}
+	return &Generator{cache: cache, templates: make(map[string]*template.Template), templateSets: make(map[string]*template.Template), textTemplates: make(map[Backend]map[string]*texttemplate.Template), gtemplates: make(map[string]*Template), htmls: make(map[string]string), baseDir: baseDir, delimLeft: defaultDelimLeft, delimRight: defaultDelimRight}
 }
 
 // formatLf returns a string whose line feed codes are replaced with LF.
@@ -272,7 +691,10 @@ func topElement(s string) bool {
 }
 
 // appendChildren fetches the lines and appends child elements to the element.
+// A line which has an error is skipped with its children and the errors are
+// returned as an ErrorList.
 func appendChildren(parent Container, lines []string, i *int, l *int, parentIndent int, parentRawContent bool, parentType string, tpl *Template) error {
+	var errs ErrorList
 	for *i < *l {
 		line := lines[*i]
 		if empty(line) {
@@ -284,29 +706,33 @@ func appendChildren(parent Container, lines []string, i *int, l *int, parentInde
 		case parentRawContent || parentType == TypeContent:
 			switch {
 			case indent < parentIndent+1:
-				return nil
+				return errs.Err()
 			default:
 				if err := appendChild(parent, &line, &indent, lines, i, l, tpl); err != nil {
-					return err
+					errs.add(tpl, *i, err)
 				}
 			}
 		default:
 			switch {
 			case indent < parentIndent+1:
-				return nil
+				return errs.Err()
 			case indent == parentIndent+1:
 				if err := appendChild(parent, &line, &indent, lines, i, l, tpl); err != nil {
-					return err
+					errs.add(tpl, *i, err)
 				}
 			case indent > parentIndent+1:
-				return fmt.Errorf("the indent of the line %d is invalid. [template: %s][lineno: %d][line: %s]", *i+1, tpl.Path, *i+1, strings.TrimSpace(line))
+				errs.add(tpl, *i+1, fmt.Errorf("the indent of the line %d is invalid. [template: %s][lineno: %d][line: %s]", *i+1, templatePath(tpl), *i+1, strings.TrimSpace(line)))
+				*i++
+				skipLines(lines, i, l, parentIndent+1)
 			}
 		}
 	}
-	return nil
+	return errs.Err()
 }
 
-// appendChild appends the child element to the parent element.
+// appendChild appends the child element to the parent element. A line which
+// has an error is skipped with its children and the errors are returned as
+// an ErrorList.
 func appendChild(parent Container, line *string, indent *int, lines []string, i *int, l *int, tpl *Template) error {
 	var child *Element
 	var err error
@@ -317,15 +743,40 @@ func appendChild(parent Container, line *string, indent *int, lines []string, i
 		child, err = NewElement(*line, *i+1, *indent, p, nil, nil)
 	}
 	if err != nil {
-		return err
+		var errs ErrorList
+		errs.add(tpl, *i+1, err)
+		*i++
+		skipLines(lines, i, l, *indent)
+		return errs.Err()
 	}
 	parent.AppendChild(child)
 	*i++
-	err = appendChildren(child, lines, i, l, child.Indent, child.RawContent, child.Type, tpl)
-	if err != nil {
-		return err
+	var errs ErrorList
+	inner := child.innermost()
+	if err := appendChildren(inner, lines, i, l, inner.Indent, inner.RawContent, inner.Type, tpl); err != nil {
+		errs.add(tpl, *i, err)
+	}
+	if err := child.validate(); err != nil {
+		errs.add(tpl, child.LineNo, err)
+	}
+	return errs.Err()
+}
+
+// skipLines skips the following lines which are empty or indented deeper
+// than the indent.
+func skipLines(lines []string, i *int, l *int, parentIndent int) {
+	for *i < *l && (empty(lines[*i]) || indent(lines[*i]) > parentIndent) {
+		*i++
 	}
-	return nil
+}
+
+// templatePath returns the template's path or an empty string if the
+// template is nil.
+func templatePath(tpl *Template) string {
+	if tpl == nil {
+		return ""
+	}
+	return tpl.Path
 }
 
 // isExtends returns if the line's prefix is "extends" or not.
@@ -337,3 +788,39 @@ func isExtends(line string) bool {
 func isBlock(line string) bool {
 	return strings.HasPrefix(line, "block ") || line == "block"
 }
+
+// formatHTML formats the HTML source codes except for the contents of the
+// elements which preserve whitespaces.
+func formatHTML(html string) string {
+	var preserved []string
+	for _, re := range preformattedElements {
+		html = re.ReplaceAllStringFunc(html, func(s string) string {
+			preserved = append(preserved, s)
+			return fmt.Sprintf("<!--gold:preserved:%d-->", len(preserved)-1)
+		})
+	}
+	html = gohtml.Format(html)
+	for i := len(preserved) - 1; i >= 0; i-- {
+		html = strings.Replace(html, fmt.Sprintf("<!--gold:preserved:%d-->", i), preserved[i], 1)
+	}
+	return html
+}
+
+// globRoot returns the longest leading directory of the pattern which has
+// no meta characters.
+func globRoot(pattern string) string {
+	dir := filepath.Dir(pattern)
+	for strings.ContainsAny(dir, "*?[\\") {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// templateName returns the name of the template file relative to the root
+// directory without the extension.
+func templateName(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		path = rel
+	}
+	return strings.TrimSuffix(filepath.ToSlash(path), Extension)
+}
@@ -1,9 +1,14 @@
 package gold
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"html/template"
 	"strings"
+	"sync"
 	"testing"
+	texttemplate "text/template"
 )
 
 func TestGeneratorParseFile(t *testing.T) {
@@ -42,6 +47,45 @@ func TestGeneratorParseFile(t *testing.T) {
 		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
 	}
 
+	// When a template defines a named template and calls it.
+	g = NewGenerator(false)
+	tpl, err = g.ParseFile("./test/TestGeneratorParseFile/013.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	if err := tpl.Execute(&bf, "Gold"); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<table><tr><td>Gold</td></tr></table>"
+	if bf.String() != expectedString {
+		t.Errorf("Output should be %s. [actual: %s]", expectedString, bf.String())
+	}
+
+	// When a template calls an undefined template.
+	g = NewGenerator(false)
+	_, err = g.ParseFile("./test/TestGeneratorParseFile/014.gold")
+	expectedErrMsg = "the template row is not defined. [template: ./test/TestGeneratorParseFile/014.gold][lineno: 2][line: template \"row\" .]"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When an else element does not follow a control element.
+	g = NewGenerator(false)
+	_, err = g.ParseFile("./test/TestGeneratorParseFile/015.gold")
+	expectedErrMsg = "The else element has to follow an if, range, with or each element. (line no: 3)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When a when element has a duplicated value.
+	g = NewGenerator(false)
+	_, err = g.ParseFile("./test/TestGeneratorParseFile/016.gold")
+	expectedErrMsg = `The when element has a duplicated value "active". (line no: 4)`
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
 	// When cache is true and g.ParseFile returns tpl.
 	g = NewGenerator(true)
 	_, err = g.ParseFile("./test/TestGeneratorParseFile/004.gold")
@@ -49,6 +93,650 @@ func TestGeneratorParseFile(t *testing.T) {
 		t.Errorf("An error(%s) occurred.", err.Error())
 	}
 }
+func TestGeneratorParseXMLFile(t *testing.T) {
+	g := NewGenerator(true)
+	tpl, err := g.ParseXMLFile("./test/TestGeneratorParseXMLFile/001.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	data := map[string]interface{}{"Title": "Tom & Jerry", "URL": "/feed?a=1&b=2", "Items": []string{"<i>"}}
+	if err := tpl.Execute(&bf, data); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<?xml version="1.0" encoding="utf-8" ?><rss xmlns:atom="http://www.w3.org/2005/Atom"><channel><title>Tom &amp; Jerry</title><atom:link href="/feed?a=1&amp;b=2" /><item><title>&lt;i&gt;</title><guid isPermaLink="isPermaLink" /></item></channel></rss>`
+	if bf.String() != expectedString {
+		t.Errorf("XML should be %s. [actual: %s]", expectedString, bf.String())
+	}
+
+	// When cache is true and a cached template exists.
+	cached, err := g.ParseXMLFile("./test/TestGeneratorParseXMLFile/001.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if cached != tpl {
+		t.Errorf("Returned value is invalid.")
+	}
+
+	// When the file does not exist.
+	if _, err := g.ParseXMLFile("./test/TestGeneratorParseXMLFile/none.gold"); err == nil {
+		t.Errorf("An error should be returned.")
+	}
+}
+
+func TestGeneratorParseXMLString(t *testing.T) {
+	g := NewGenerator(false)
+	src := `
+svg:svg
+  svg:rect width={{.Width}}
+  svg:text
+    = .Text
+`
+	tpl, err := g.ParseXMLString(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	if err := tpl.Execute(&bf, map[string]interface{}{"Width": `"10"`, "Text": "a<b"}); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<svg:svg><svg:rect width="&#34;10&#34;" /><svg:text>a&lt;b</svg:text></svg:svg>`
+	if bf.String() != expectedString {
+		t.Errorf("XML should be %s. [actual: %s]", expectedString, bf.String())
+	}
+
+	// When the actions are in the child text lines and the literals.
+	src = `
+rss
+  item
+    {{.Name}}
+  link
+    | {{.Name}} & {{if .Name}}a{{end}}
+`
+	tpl, err = g.ParseXMLString(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	bf = bytes.Buffer{}
+	if err := tpl.Execute(&bf, map[string]interface{}{"Name": "<script>&"}); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = "<rss><item>&lt;script&gt;&amp;\n</item><link>&lt;script&gt;&amp; &amp; a</link></rss>"
+	if bf.String() != expectedString {
+		t.Errorf("XML should be %s. [actual: %s]", expectedString, bf.String())
+	}
+}
+
+func TestGeneratorCompile(t *testing.T) {
+	// When the backend is HTML.
+	g := NewGenerator(false)
+	tpl, err := g.Compile("./test/TestGeneratorParseFile/013.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if _, ok := tpl.(*template.Template); !ok {
+		t.Errorf("An HTML template should be returned.")
+	}
+
+	// When the backend is text.
+	g.SetBackend(TextBackend)
+	tpl, err = g.Compile("./test/TestGeneratorParseFile/013.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if _, ok := tpl.(*texttemplate.Template); !ok {
+		t.Errorf("A text template should be returned.")
+	}
+
+	// When the file does not exist.
+	if _, err := g.Compile("./test/TestGeneratorParseFile/none.gold"); err == nil {
+		t.Errorf("An error should be returned.")
+	}
+}
+
+func TestGeneratorCompileString(t *testing.T) {
+	g := NewGenerator(true).SetBackend(TextBackend).SetHelpers(map[string]interface{}{"upper": strings.ToUpper})
+	stringTemplates := map[string]string{
+		"base": `
+| Hello {{upper .Name}},
+block body
+| Bye
+`,
+		"mail": `
+extends base
+block body
+  | Visit {{.URL}}
+  each item in .Items
+    | - {{$item}}
+`,
+	}
+	tpl, err := g.CompileString(stringTemplates, "mail")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	if err := tpl.Execute(&bf, map[string]interface{}{"Name": "<b>", "URL": "/a?b=1&c=2", "Items": []string{"a", "b"}}); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "Hello <B>,\nVisit /a?b=1&c=2\n- a\n- b\nBye\n"
+	if bf.String() != expectedString {
+		t.Errorf("Text should be %q. [actual: %q]", expectedString, bf.String())
+	}
+
+	// When cache is true and a cached template exists.
+	cached, err := g.CompileString(stringTemplates, "mail")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if cached != tpl {
+		t.Errorf("Returned value is invalid.")
+	}
+
+	// When the template is invalid.
+	if _, err := g.CompileString(map[string]string{"src": "block"}, "src"); err == nil {
+		t.Errorf("An error should be returned.")
+	}
+}
+
+func TestGeneratorParseTree(t *testing.T) {
+	g := NewGenerator(false)
+	tpl, err := g.ParseTree("./test/TestGeneratorParseFile/012.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if err == nil && (tpl.Super == nil || tpl.Blocks["content"] == nil) {
+		t.Errorf("The tree is invalid.")
+	}
+
+	// When the file does not exist.
+	if _, err := g.ParseTree("./test/TestGeneratorParseFile/none.gold"); err == nil {
+		t.Errorf("An error should be returned.")
+	}
+}
+
+func TestGeneratorParseTreeErrors(t *testing.T) {
+	g := NewGenerator(false)
+	stringTemplates := map[string]string{
+		"base": "html\n  body\n    div#a#b\n    block content",
+		"page": `extends base
+block
+  p skipped
+block content
+  p#x#y Wrong
+    span skipped
+  p OK
+      span Bad indent
+        span skipped
+  p Last
+`,
+	}
+	tpl, err := g.ParseTreeString(stringTemplates, "page")
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Errorf("An ErrorList should be returned. [actual: %v]", err)
+		return
+	}
+	expected := []struct {
+		template string
+		lineNo   int
+		msg      string
+	}{
+		{"base", 3, "The number of the element id has to be one. (line no: 3)"},
+		{"page", 2, "the line tokens length is invalid. (expected: 2, actual: 1, line no: 2, template: page, line: block)"},
+		{"page", 5, "The number of the element id has to be one. (line no: 5)"},
+		{"page", 8, "the indent of the line 8 is invalid. [template: page][lineno: 8][line: span Bad indent]"},
+	}
+	if len(errs) != len(expected) {
+		t.Errorf("The number of the errors should be %d. [actual: %d, %s]", len(expected), len(errs), errs.Error())
+		return
+	}
+	for i, e := range errs {
+		if e.Template != expected[i].template || e.LineNo != expected[i].lineNo || e.Error() != expected[i].msg {
+			t.Errorf("Error %d should be %+v. [actual: %+v]", i, expected[i], *e)
+		}
+	}
+	expectedErrMsg := "The number of the element id has to be one. (line no: 3) (and 3 more errors)"
+	if err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// The partial tree is returned.
+	if tpl == nil || tpl.Super == nil {
+		t.Errorf("The partial tree should be returned.")
+		return
+	}
+	expectedString := `extends base
+block content
+  p OK
+  p Last
+`
+	if src := Sprint(tpl); src != expectedString {
+		t.Errorf("Source should be %s. [actual: %s]", expectedString, src)
+	}
+}
+
+func TestGeneratorParseStringSharedSuper(t *testing.T) {
+	g := NewGenerator(true)
+	stringTemplates := map[string]string{
+		"layout": "html\n  body\n    block content\n      p Default",
+		"page1":  "extends layout\nblock content\n  p Page1",
+		"page2":  "extends layout\nblock content\n  p Page2",
+		"page3":  "extends layout",
+	}
+	page1, err := g.ParseTreeString(stringTemplates, "page1")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+		return
+	}
+	page2, err := g.ParseTreeString(stringTemplates, "page2")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+		return
+	}
+	if page1.Super != page2.Super {
+		t.Errorf("The cached super template should be shared.")
+	}
+	if page1.Super.Sub != nil {
+		t.Errorf("The super template should not be modified.")
+	}
+	html, err := page1.Html(stringTemplates, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<html><body><p>Page1</p></body></html>"
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the templates are parsed in parallel.
+	expected := map[string]string{
+		"page1": "<html><body><p>Page1</p></body></html>",
+		"page2": "<html><body><p>Page2</p></body></html>",
+		"page3": "<html><body><p>Default</p></body></html>",
+	}
+	g = NewGenerator(true)
+	var wg sync.WaitGroup
+	errs := make(chan error, 30)
+	for i := 0; i < 10; i++ {
+		for name, expectedString := range expected {
+			wg.Add(1)
+			go func(name, expectedString string) {
+				defer wg.Done()
+				_, html, err := g.ParseStringWithHTML(stringTemplates, name)
+				switch {
+				case err != nil:
+					errs <- err
+				case html != expectedString:
+					errs <- fmt.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+				}
+			}(name, expectedString)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+}
+
+func TestGeneratorParseStringInheritanceChain(t *testing.T) {
+	g := NewGenerator(false)
+	stringTemplates := map[string]string{
+		"base": `html
+  head
+    title
+      block title
+        | Base
+  body
+    block content
+      p Base content
+    block footer
+      p Base footer`,
+		"section": `extends base
+block content
+  div.section
+    block sidebar
+      p Section sidebar
+    block main
+      p Section main`,
+		"page": `extends section
+block title
+  | Page
+block main
+  p Page main`,
+		"article": `extends page
+block footer
+  p Article footer
+block sidebar
+  p Article sidebar
+block main
+  p Article main`,
+	}
+	// When the block is overridden only by the third level template.
+	_, html, err := g.ParseStringWithHTML(stringTemplates, "page")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<html><head><title>Page</title></head><body><div class="section"><p>Section sidebar</p><p>Page main</p></div><p>Base footer</p></body></html>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the blocks are overridden by the fourth level template.
+	_, html, err = g.ParseStringWithHTML(stringTemplates, "article")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `<html><head><title>Page</title></head><body><div class="section"><p>Article sidebar</p><p>Article main</p></div><p>Article footer</p></body></html>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the second level template is rendered.
+	_, html, err = g.ParseStringWithHTML(stringTemplates, "section")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `<html><head><title>Base</title></head><body><div class="section"><p>Section sidebar</p><p>Section main</p></div><p>Base footer</p></body></html>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+}
+
+func TestGeneratorParseStringSuper(t *testing.T) {
+	g := NewGenerator(false)
+	stringTemplates := map[string]string{
+		"base": `html
+  head
+    block scripts
+      script src=/base.js
+  body
+    block content
+      p Base`,
+		"section": `extends base
+block scripts
+  super
+  script src=/section.js
+block content
+  div.section
+    block main`,
+		"page": `extends section
+block scripts
+  script src=/first.js
+  super
+  script src=/page.js
+block main
+  p Main`,
+		"invalid": `extends base
+block content
+  super
+block other
+  super`,
+	}
+	// When the super elements are chained.
+	_, html, err := g.ParseStringWithHTML(stringTemplates, "page")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<html><head><script src="/first.js"></script><script src="/base.js"></script><script src="/section.js"></script><script src="/page.js"></script></head><body><div class="section"><p>Main</p></div></body></html>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the super element renders the children of the block element.
+	_, html, err = g.ParseStringWithHTML(stringTemplates, "section")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `<html><head><script src="/base.js"></script><script src="/section.js"></script></head><body><div class="section"></div></body></html>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the super templates do not define the block.
+	_, err = g.ParseTreeString(stringTemplates, "invalid")
+	expectedErrMsg := "The block other is not defined in the super templates. (line no: 5)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the super element is not in a block.
+	_, err = g.ParseString(map[string]string{"top": "div\n  super"}, "top")
+	expectedErrMsg = "The super element has to be in a block. (line no: 2)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
+
+func TestGeneratorParseStringIncludeParams(t *testing.T) {
+	g := NewGenerator(false)
+	stringTemplates := map[string]string{
+		"index": `div
+  include card title="Hello world" href=/a?b=c size=3
+  include card title=Untitled`,
+		"card": `@param title
+@param href="#"
+@param size:int?
+a href=%{href} data-size=%{size}
+  | %{title}
+  include badge`,
+		"badge":   "span %{title}",
+		"missing": "include card",
+		"unknown": "include card title=a color=red",
+		"invalid": "include card title=a size=large",
+		"legacy":  "include badge title=Legacy",
+		"element": "object\n  param name=a value=b",
+		"top":     "param name=a\np %{name}",
+	}
+	// When the parameters are given and the default value is used.
+	_, html, err := g.ParseStringWithHTML(stringTemplates, "index")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<div><a href="/a?b=c" data-size="3">Hello world<span>%{title}</span></a><a href="#" data-size="0">Untitled<span>%{title}</span></a></div>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the template does not declare parameters.
+	_, html, err = g.ParseStringWithHTML(stringTemplates, "legacy")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `<span>Legacy</span>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the param lines are param elements.
+	for name, expectedString := range map[string]string{"element": `<object><param name="a" value="b"></object>`, "top": `<param name="a"><p>%{name}</p>`} {
+		_, html, err = g.ParseStringWithHTML(stringTemplates, name)
+		if err != nil {
+			t.Errorf("An error(%s) occurred.", err.Error())
+		}
+		if html != expectedString {
+			t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+		}
+	}
+
+	// When a required parameter is not given.
+	_, err = g.ParseString(stringTemplates, "missing")
+	expectedErrMsg := "The required parameter title of the included template card is not given. (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When an unknown parameter is given.
+	_, err = g.ParseString(stringTemplates, "unknown")
+	expectedErrMsg = "The parameter color is not declared by the included template card. (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When a parameter's value is not valid for its type.
+	_, err = g.ParseString(stringTemplates, "invalid")
+	expectedErrMsg = "The parameter size of the included template card is not a valid int. [value: large] (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the param lines are invalid.
+	_, err = g.ParseTreeString(map[string]string{"params": "@param\n@param a\n@param a=b\n@param b:date\n@param c:bool=yes\n@param :int\np"}, "params")
+	errs, ok := err.(ErrorList)
+	if !ok || len(errs) != 5 {
+		t.Errorf("An ErrorList which has 5 errors should be returned. [actual: %v]", err)
+		return
+	}
+	for i, expectedErrMsg := range []string{
+		"the param line is invalid. (line no: 1, template: params, line: @param)",
+		"the param a is declared twice. (line no: 3, template: params)",
+		"the param type date is not supported. (line no: 4, template: params)",
+		"the default value yes of the param c is not a valid bool. (line no: 5, template: params)",
+		"the param line is invalid. (line no: 6, template: params, line: @param :int)",
+	} {
+		if errs[i].Error() != expectedErrMsg {
+			t.Errorf("Error(%s) should be returned. [actual: %s]", expectedErrMsg, errs[i].Error())
+		}
+	}
+}
+
+func TestGeneratorAddTransform(t *testing.T) {
+	g := NewGenerator(false).AddTransform(func(tpl *Template) error {
+		Inspect(tpl, func(node Node) bool {
+			if e, ok := node.(*Element); ok && e.Kind() == KindTag {
+				switch {
+				case e.Tag == "img":
+					e.SetAttribute("loading", "lazy")
+				case e.Tag == "a" && strings.HasPrefix(e.Attributes["href"], "https://"):
+					e.SetAttribute("rel", "noopener")
+				}
+			}
+			return true
+		})
+		return nil
+	}).AddPreprocessor(func(path, src string) (string, error) {
+		return strings.Replace(src, "@@assets", "/static", -1), nil
+	})
+	_, html, err := g.ParseFileWithHTML("./test/TestGeneratorAddTransform/001.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<div><img src="/a.png" loading="lazy"><img src="/static/b.png" loading="lazy"><a href="https://example.com" rel="noopener">External</a><a href="/about">About</a></div>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the transform returns an error.
+	g = NewGenerator(false).AddTransform(func(tpl *Template) error {
+		return errors.New("failed")
+	})
+	_, err = g.ParseString(map[string]string{"src": "p"}, "src")
+	expectedErrMsg := "the transform returned an error: failed [template: src]"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the preprocessor returns an error.
+	g = NewGenerator(false).AddPreprocessor(func(path, src string) (string, error) {
+		return "", errors.New("failed")
+	})
+	_, err = g.ParseString(map[string]string{"src": "p"}, "src")
+	expectedErrMsg = "the preprocessor returned an error: failed [template: src]"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
+
+func TestGeneratorParseGlob(t *testing.T) {
+	// When the pattern matches no files.
+	g := NewGenerator(false)
+	_, err := g.ParseGlob("./test/TestGeneratorParseGlob/*.html")
+	expectedErrMsg := "the pattern matched no files. [pattern: ./test/TestGeneratorParseGlob/*.html]"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the files call each other.
+	g = NewGenerator(true)
+	tpl, err := g.ParseGlob("./test/TestGeneratorParseGlob/*.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&bf, "index", "Gold"); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<div><footer>Gold</footer>\n</div>"
+	if bf.String() != expectedString {
+		t.Errorf("Output should be %s. [actual: %s]", expectedString, bf.String())
+	}
+
+	// When the template set is cached.
+	cachedTpl, err := g.ParseGlob("./test/TestGeneratorParseGlob/*.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if cachedTpl != tpl {
+		t.Errorf("Returned value is invalid.")
+	}
+
+	// When the pattern is equal to the path of a cached template file.
+	g = NewGenerator(true)
+	fileTpl, err := g.ParseFile("./test/TestGeneratorParseGlob/index.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	setTpl, err := g.ParseGlob("./test/TestGeneratorParseGlob/index.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if setTpl == fileTpl || setTpl.Lookup("index") == nil {
+		t.Errorf("Returned value is invalid.")
+	}
+	if cachedTpl, _ := g.ParseFile("./test/TestGeneratorParseGlob/index.gold"); cachedTpl != fileTpl {
+		t.Errorf("Returned value is invalid.")
+	}
+}
+
+func TestGeneratorParseDir(t *testing.T) {
+	// When the directory does not exist.
+	g := NewGenerator(false)
+	_, err := g.ParseDir("./test/TestGeneratorParseDir/somedir")
+	expectedErrMsg := "lstat ./test/TestGeneratorParseDir/somedir: no such file or directory"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the files in subdirectories are called.
+	g = NewGenerator(false)
+	tpl, err := g.ParseDir("./test/TestGeneratorParseDir")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	data := map[string]string{"Title": "Gold", "Msg": "Hello"}
+	if err := tpl.ExecuteTemplate(&bf, "index", data); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<html><body><header><h1>Gold</h1></header>\n<p>Hello</p></body></html>"
+	if bf.String() != expectedString {
+		t.Errorf("Output should be %s. [actual: %s]", expectedString, bf.String())
+	}
+
+	// When the template set is cached.
+	g = NewGenerator(true)
+	tpl, err = g.ParseDir("./test/TestGeneratorParseDir")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if cachedTpl, _ := g.ParseDir("./test/TestGeneratorParseDir"); cachedTpl != tpl {
+		t.Errorf("Returned value is invalid.")
+	}
+	if g.templates["./test/TestGeneratorParseDir"] != nil {
+		t.Errorf("The template set should not be cached as a template file.")
+	}
+}
+
 func TestGeneratorParseString(t *testing.T) {
 	g := &Generator{}
 	parent := `
@@ -83,6 +771,246 @@ p This is an included line.
 	}
 }
 
+func TestGeneratorParseStringControl(t *testing.T) {
+	g := NewGenerator(false).Delims("<%", "%>")
+	src := `
+ul
+  if .Admin
+    li Admin
+  else if .User
+    li User
+  else
+    li Guest
+  each i, item in .Items
+    li <%$i%>:<%$item%>
+`
+	tpl, err := g.ParseString(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	data := map[string]interface{}{"User": true, "Items": []string{"a", "b"}}
+	if err := tpl.Execute(&bf, data); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<ul><li>User</li><li>0:a</li><li>1:b</li></ul>"
+	if bf.String() != expectedString {
+		t.Errorf("Output should be %s. [actual: %s]", expectedString, bf.String())
+	}
+}
+
+func TestGeneratorParseStringCase(t *testing.T) {
+	g := NewGenerator(false)
+	src := `
+case .Status
+  when "active"
+    p Active
+  when "banned", "deleted"
+    p Inactive
+  default
+    p Unknown
+`
+	tpl, err := g.ParseString(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	for status, expectedString := range map[string]string{"active": "<p>Active</p>", "deleted": "<p>Inactive</p>", "new": "<p>Unknown</p>"} {
+		var bf bytes.Buffer
+		if err := tpl.Execute(&bf, map[string]string{"Status": status}); err != nil {
+			t.Errorf("An error(%s) occurred.", err.Error())
+		}
+		if bf.String() != expectedString {
+			t.Errorf("Output should be %s. [actual: %s]", expectedString, bf.String())
+		}
+	}
+
+	// When the values are single-quoted.
+	src = `
+case .Status
+  when 'a'
+    p A
+  when 'ab', 'it\'s'
+    p B
+`
+	tpl, err = g.ParseString(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	for status, expectedString := range map[string]string{"a": "<p>A</p>", "ab": "<p>B</p>", "it's": "<p>B</p>", "c": ""} {
+		var bf bytes.Buffer
+		if err := tpl.Execute(&bf, map[string]string{"Status": status}); err != nil {
+			t.Errorf("An error(%s) occurred.", err.Error())
+		}
+		if bf.String() != expectedString {
+			t.Errorf("Output should be %s. [actual: %s]", expectedString, bf.String())
+		}
+	}
+
+	// When a single-quoted value duplicates a double-quoted value.
+	src = `
+case .Status
+  when "a"
+    p A
+  when 'a'
+    p B
+`
+	_, err = g.ParseString(map[string]string{"src": src}, "src")
+	expectedErrMsg := `The when element has a duplicated value "a". (line no: 5)`
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
+
+func TestGeneratorParseStringTemplateTag(t *testing.T) {
+	g := NewGenerator(false)
+	src := `
+define row
+  td {{.}}
+template id=row
+  tr
+    td Hello
+template foo
+table
+  tr
+    template "row" .
+`
+	_, html, err := g.ParseStringWithHTML(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `{{define "row"}}<td>{{.}}</td>{{end}}<template id="row"><tr><td>Hello</td></tr></template><template>foo</template><table><tr>{{template "row" .}}</tr></table>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the names of the define elements are not quoted.
+	stringTemplates := map[string]string{
+		"base": "define cell\n  td {{.}}\nblock content",
+		"page": "extends base\nblock content\n  table\n    tr\n      template row .X\n      template cell .Y\n      template other\ndefine row\n  td {{.}}",
+	}
+	_, html, err = g.ParseStringWithHTML(stringTemplates, "page")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `{{define "cell"}}<td>{{.}}</td>{{end}}<table><tr>{{template "row" .X}}{{template "cell" .Y}}<template>other</template></tr></table>{{define "row"}}<td>{{.}}</td>{{end}}`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+}
+
+func TestGeneratorParseStringBlockExpansion(t *testing.T) {
+	g := NewGenerator(false)
+	src := `
+ul
+  li: a href=/
+    span Home
+`
+	_, html, err := g.ParseStringWithHTML(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<ul><li><a href="/"><span>Home</span></a></li></ul>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+}
+
+func TestGeneratorSetMinify(t *testing.T) {
+	g := NewGenerator(false).SetMinify(true).SetMinifyRawContent(true)
+	src := `
+div#main title="a b"
+  p   Hello    world {{"a   b"}}
+  p.
+    Gold   is a
+    template engine.
+  pre.
+    keep   this
+  {{if .X}}
+    span x
+  {{end}}
+  style
+    a , b {
+      color: red;
+    }
+`
+	_, html, err := g.ParseStringWithHTML(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<div id=main title=\"a b\"><p>Hello world {{\"a   b\"}}</p><p>Gold is a template engine.</p><pre>keep   this</pre>{{if .X}}<span>x</span>{{end}}<style>a,b{color: red;}</style></div>"
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+}
+
+func TestGeneratorRegisterFilter(t *testing.T) {
+	g := NewGenerator(false).RegisterFilter("upper", func(b []byte) ([]byte, error) {
+		return bytes.ToUpper(b), nil
+	})
+	src := `
+div
+  :upper
+    hello
+      gold
+  :markdown
+    *Gold*
+`
+	_, html, err := g.ParseStringWithHTML(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "<div>HELLO\n  GOLD<p><em>Gold</em></p></div>"
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the filter returns an error.
+	g.RegisterFilter("fail", func(b []byte) ([]byte, error) {
+		return nil, errors.New("failed")
+	})
+	_, err = g.ParseString(map[string]string{"src": ":fail\n  a"}, "src")
+	expectedErrMsg := "The filter fail returned an error: failed (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+
+	// When the filter is not registered.
+	_, err = g.ParseString(map[string]string{"src": ":none\n  a"}, "src")
+	expectedErrMsg = "The filter none is not registered. (line no: 1)"
+	if err == nil || err.Error() != expectedErrMsg {
+		t.Errorf("Error(%s) should be returned.", expectedErrMsg)
+	}
+}
+
+func TestGeneratorRegisterDoctype(t *testing.T) {
+	g := NewGenerator(false).RegisterDoctype("custom", "<!DOCTYPE custom>")
+	src := `
+doctype custom
+div
+  br
+  input type=checkbox [checked]
+`
+	_, html, err := g.ParseStringWithHTML(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<!DOCTYPE custom><div><br><input type="checkbox" checked></div>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+
+	// When the doctype is based on XML.
+	g.RegisterDoctype("custom", `<?xml version="1.0" encoding="utf-8" ?>`)
+	_, html, err = g.ParseStringWithHTML(map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `<?xml version="1.0" encoding="utf-8" ?><div><br /><input type="checkbox" checked="checked" /></div>`
+	if html != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, html)
+	}
+}
+
 func TestParse(t *testing.T) {
 	// When cache is true and Parse returns a cached template.
 	gtmplt := &Template{}
@@ -420,3 +1348,22 @@ func TestGeneratorSetHelpers(t *testing.T) {
 	g := NewGenerator(false)
 	g.SetHelpers(template.FuncMap{"title": strings.Title})
 }
+
+func TestGlobRoot(t *testing.T) {
+	if globRoot("views/*/*.gold") != "views" {
+		t.Errorf("Returned value is invalid.")
+	}
+}
+
+func TestTemplateName(t *testing.T) {
+	if templateName("views", "views/layouts/base.gold") != "layouts/base" {
+		t.Errorf("Returned value is invalid.")
+	}
+}
+
+func TestFormatHTML(t *testing.T) {
+	html := formatHTML("<div><pre>  a\n    b</pre><textarea>\n c</textarea></div>")
+	if !strings.Contains(html, "<pre>  a\n    b</pre>") || !strings.Contains(html, "<textarea>\n c</textarea>") {
+		t.Errorf("Returned value is invalid. [actual: %s]", html)
+	}
+}
@@ -0,0 +1,116 @@
+package gold
+
+import (
+	"encoding/json"
+)
+
+// A jsonAttribute represents an attribute of a tag element in JSON.
+type jsonAttribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// A jsonLocation represents a location of a node in JSON.
+type jsonLocation struct {
+	Template string `json:"template"`
+	Line     int    `json:"line"`
+}
+
+// A jsonNode represents a node of a parsed Gold template in JSON.
+type jsonNode struct {
+	Kind              string          `json:"kind"`
+	Path              string          `json:"path,omitempty"`
+	Template          string          `json:"template,omitempty"`
+	Line              int             `json:"line,omitempty"`
+	Column            int             `json:"column,omitempty"`
+	Name              string          `json:"name,omitempty"`
+	Tag               string          `json:"tag,omitempty"`
+	Id                string          `json:"id,omitempty"`
+	Classes           []string        `json:"classes,omitempty"`
+	Attributes        []jsonAttribute `json:"attributes,omitempty"`
+	BooleanAttributes []string        `json:"booleanAttributes,omitempty"`
+	Text              string          `json:"text,omitempty"`
+	Extends           string          `json:"extends,omitempty"`
+	Super             *Template       `json:"super,omitempty"`
+	Params            []*Param        `json:"params,omitempty"`
+	Include           string          `json:"include,omitempty"`
+	Parameters        []string        `json:"parameters,omitempty"`
+	Overrides         *jsonLocation   `json:"overrides,omitempty"`
+	Nodes             []Node          `json:"nodes,omitempty"`
+}
+
+// MarshalJSON returns the JSON encoding of the template. The super template
+// is encoded as "super" and the path which the template extends is resolved
+// as "extends".
+func (t *Template) MarshalJSON() ([]byte, error) {
+	n := jsonNode{Kind: t.Kind().String(), Path: t.Path, Super: t.Super, Params: t.Params, Nodes: t.Nodes()}
+	if t.Super != nil {
+		n.Extends = t.Super.Path
+	}
+	return json.Marshal(n)
+}
+
+// MarshalJSON returns the JSON encoding of the block. The location of the
+// super template's block or block element which the block overrides is
+// encoded as "overrides".
+func (b *Block) MarshalJSON() ([]byte, error) {
+	n := jsonNode{Kind: b.Kind().String(), Name: b.Name, Line: b.LineNo, Nodes: b.Nodes()}
+	if b.Template != nil {
+		n.Template = b.Template.Path
+		for super := b.Template.Super; super != nil && n.Overrides == nil; super = super.Super {
+			if superBlock, prs := super.Blocks[b.Name]; prs {
+				n.Overrides = &jsonLocation{Template: super.Path, Line: superBlock.LineNo}
+			} else if e := super.blockElement(b.Name); e != nil {
+				n.Overrides = &jsonLocation{Template: super.Path, Line: e.LineNo}
+			}
+		}
+	}
+	return json.Marshal(n)
+}
+
+// MarshalJSON returns the JSON encoding of the element. The path of the
+// template which an include element includes is resolved as "include". The
+// columns of inline elements are not encoded.
+func (e *Element) MarshalJSON() ([]byte, error) {
+	n := jsonNode{Kind: e.Kind().String(), Line: e.LineNo, Nodes: e.Nodes()}
+	if !e.Inline {
+		n.Column = e.Col
+	}
+	tpl := e.getTemplate()
+	if tpl != nil {
+		n.Template = tpl.Path
+	}
+	switch {
+	case e.Type == TypeTag && !e.comment():
+		n.Tag, n.Id, n.Classes, n.BooleanAttributes = e.Tag, e.Id, e.Classes, e.SingleAttributes
+		for _, k := range e.attributeNames() {
+			n.Attributes = append(n.Attributes, jsonAttribute{Name: k, Value: e.Attributes[k]})
+		}
+		n.Text = e.textValue()
+		if e.hasInlineChildren() {
+			n.Text = e.inlineSource()
+		}
+	case e.Type == TypeLiteral:
+		n.Text = e.literalValue()
+		if e.hasInlineChildren() {
+			n.Text = e.inlineSource()
+		}
+	case e.Type == TypeBlock && len(e.Tokens) > 1:
+		n.Name = e.Tokens[1]
+		n.Text = e.Text
+	case e.Type == TypeInclude && len(e.Tokens) > 1:
+		var stringTemplates map[string]string
+		if tpl != nil {
+			stringTemplates = tpl.stringTemplates
+		}
+		incTplPath, addBaseDir := e.includedTemplatePath(stringTemplates)
+		if addBaseDir && tpl != nil && tpl.Generator != nil {
+			incTplPath = Path(tpl.Generator.baseDir, incTplPath)
+		}
+		n.Include, n.Parameters = incTplPath, e.Tokens[IncludeParaStartIndex:]
+		n.Text = e.Text
+	default:
+		n.Text = e.Text
+	}
+	return json.Marshal(n)
+}
@@ -0,0 +1,68 @@
+package gold
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTemplateMarshalJSON(t *testing.T) {
+	g := NewGenerator(false)
+	stringTemplates := map[string]string{
+		"base": "html\n  body\n    block content",
+		"page": `extends base
+block content
+  a#top.link.nav href=/x title="a b" [hidden] Go #{.Name}
+  | Hello
+  include footer year=2014
+`,
+		"footer": "footer %{year}",
+	}
+	tpl, err := g.ParseTreeString(stringTemplates, "page")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+		return
+	}
+	b, err := json.Marshal(tpl)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `{"kind":"template","path":"page","extends":"base",` +
+		`"super":{"kind":"template","path":"base","nodes":[{"kind":"tag","template":"base","line":1,"column":1,"tag":"html","nodes":[{"kind":"tag","template":"base","line":2,"column":3,"tag":"body","nodes":[{"kind":"blockElement","template":"base","line":3,"column":5,"name":"content","text":"block content"}]}]}]},` +
+		`"nodes":[{"kind":"block","template":"page","line":2,"name":"content","overrides":{"template":"base","line":3},"nodes":[` +
+		`{"kind":"tag","template":"page","line":3,"column":3,"tag":"a","id":"top","classes":["link","nav"],"attributes":[{"name":"href","value":"/x"},{"name":"title","value":"a b"}],"booleanAttributes":["hidden"],"text":"Go #{.Name}","nodes":[{"kind":"text","template":"page","line":3,"text":"Go "},{"kind":"outputExpression","template":"page","line":3,"text":"= .Name"}]},` +
+		`{"kind":"text","template":"page","line":4,"column":3,"text":"Hello"},` +
+		`{"kind":"include","template":"page","line":5,"column":3,"text":"include footer year=2014","include":"footer","parameters":["year=2014"]}]}]}`
+	if string(b) != expectedString {
+		t.Errorf("JSON should be %s. [actual: %s]", expectedString, string(b))
+	}
+
+	// When the template declares parameters.
+	tpl, err = g.ParseTreeString(map[string]string{"card": "@param title\n@param href=#\n@param size:int?\na href=%{href} %{title}"}, "card")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+		return
+	}
+	b, err = json.Marshal(tpl.Params)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `[{"name":"title","type":"string","required":true,"line":1},{"name":"href","type":"string","default":"#","required":false,"line":2},{"name":"size","type":"int","required":false,"line":3}]`
+	if string(b) != expectedString {
+		t.Errorf("JSON should be %s. [actual: %s]", expectedString, string(b))
+	}
+
+	// When the template is parsed from a file.
+	tpl, err = g.ParseTree("./test/TestGeneratorAddTransform/001.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+		return
+	}
+	b, err = json.Marshal(tpl.Elements[0].Children[1])
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `{"kind":"include","template":"./test/TestGeneratorAddTransform/001.gold","line":3,"column":3,"text":"include ./002","include":"./test/TestGeneratorAddTransform/./002.gold"}`
+	if string(b) != expectedString {
+		t.Errorf("JSON should be %s. [actual: %s]", expectedString, string(b))
+	}
+}
@@ -0,0 +1,269 @@
+package gold
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A TokenType represents the type of a token of a tag line.
+type TokenType int
+
+// Token types
+const (
+	TokenTag TokenType = iota
+	TokenId
+	TokenClass
+	TokenMarker
+	TokenAttributeName
+	TokenAttributeValue
+	TokenBooleanAttribute
+	TokenText
+	TokenAction
+)
+
+// tokenTypeNames holds the names of the token types.
+var tokenTypeNames = map[TokenType]string{
+	TokenTag:              "tag",
+	TokenId:               "id",
+	TokenClass:            "class",
+	TokenMarker:           "marker",
+	TokenAttributeName:    "attributeName",
+	TokenAttributeValue:   "attributeValue",
+	TokenBooleanAttribute: "booleanAttribute",
+	TokenText:             "text",
+	TokenAction:           "action",
+}
+
+// String returns the name of the token type.
+func (t TokenType) String() string {
+	return tokenTypeNames[t]
+}
+
+// A Token represents a token of a tag line. Col is the column of the token's
+// first character in the line.
+type Token struct {
+	Type  TokenType
+	Value string
+	Col   int
+}
+
+// attributeName matches the name and the equal sign of an attribute.
+var attributeName = regexp.MustCompile(`^[A-Za-z_:@][-A-Za-z0-9_:.@]*=`)
+
+// A lexer splits a tag line into tokens.
+type lexer struct {
+	s          string
+	pos        int
+	col        int
+	delimLeft  string
+	delimRight string
+	tokens     []Token
+}
+
+// lex splits the tag line into tokens. The tag line consists of a head (a
+// tag, ids, classes and markers), attributes, boolean attributes and a text.
+// The text starts at the first word which is not an attribute or at the
+// word following "|", and continues to the end of the line as it is. col is
+// the column of the line's first character.
+func lex(s string, col int, delimLeft, delimRight string) []Token {
+	l := &lexer{s: s, col: col, delimLeft: delimLeft, delimRight: delimRight}
+	l.lexHead()
+	for l.skipSpaces() {
+		switch {
+		case l.s[l.pos] == '|' && (l.pos+1 == len(l.s) || space(l.s[l.pos+1])):
+			l.pos++
+			if l.pos < len(l.s) {
+				l.pos++
+			}
+			l.lexText()
+		case l.s[l.pos] == '[' && l.lexBooleanAttribute():
+		case attributeName.MatchString(l.s[l.pos:]):
+			l.lexAttribute()
+		default:
+			l.lexText()
+		}
+	}
+	return l.tokens
+}
+
+// words splits the line into its words which are separated by whitespaces.
+// Attributes, boolean attributes ([name]), double-quoted strings and actions
+// are not separated by the whitespaces in them. A blank line has one empty
+// word.
+func words(s string, delimLeft, delimRight string) []string {
+	l := &lexer{s: s, delimLeft: delimLeft, delimRight: delimRight}
+	var words []string
+	for l.skipSpaces() {
+		start := l.pos
+		switch {
+		case l.s[l.pos] == '[' && l.lexBooleanAttribute():
+		case attributeName.MatchString(l.s[l.pos:]):
+			l.lexAttribute()
+		default:
+			l.lexWord()
+		}
+		words = append(words, l.s[start:l.pos])
+	}
+	if len(words) == 0 {
+		return []string{""}
+	}
+	return words
+}
+
+// lexWord skips a word which is not an attribute. Double-quoted strings and
+// actions in the word are skipped as they are.
+func (l *lexer) lexWord() {
+	for l.pos < len(l.s) && !space(l.s[l.pos]) {
+		switch {
+		case l.s[l.pos] == '"':
+			if end := closingQuote(l.s, l.pos); end > 0 {
+				l.pos = end
+			}
+		case l.delimLeft != "" && strings.HasPrefix(l.s[l.pos:], l.delimLeft):
+			if end := strings.Index(l.s[l.pos:], l.delimRight); end > 0 {
+				l.pos += end + len(l.delimRight) - 1
+			}
+		}
+		l.pos++
+	}
+}
+
+// emit appends the token which starts at the position to the tokens.
+func (l *lexer) emit(t TokenType, value string, pos int) {
+	l.tokens = append(l.tokens, Token{Type: t, Value: value, Col: l.col + pos})
+}
+
+// skipSpaces skips the whitespaces and returns if a token follows them or
+// not.
+func (l *lexer) skipSpaces() bool {
+	for l.pos < len(l.s) && space(l.s[l.pos]) {
+		l.pos++
+	}
+	return l.pos < len(l.s)
+}
+
+// lexHead splits the first word into a tag, ids, classes and markers (<, >,
+// ! and a trailing period).
+func (l *lexer) lexHead() {
+	end := l.pos
+	for end < len(l.s) && !space(l.s[end]) {
+		end++
+	}
+	head := l.s[:end]
+	l.pos = end
+	if head == "javascript:" {
+		l.emit(TokenTag, head, 0)
+		return
+	}
+	var markers []Token
+	for len(head) > 0 && strings.ContainsRune("<>!", rune(head[len(head)-1])) {
+		markers = append([]Token{{Type: TokenMarker, Value: head[len(head)-1:], Col: l.col + len(head) - 1}}, markers...)
+		head = head[:len(head)-1]
+	}
+	if strings.HasSuffix(head, ".") {
+		markers = append([]Token{{Type: TokenMarker, Value: ".", Col: l.col + len(head) - 1}}, markers...)
+		head = head[:len(head)-1]
+	}
+	start := 0
+	t := TokenTag
+	for i := 0; i <= len(head); i++ {
+		if i < len(head) && head[i] != '#' && head[i] != '.' {
+			continue
+		}
+		if i > start || t != TokenTag {
+			l.emit(t, head[start:i], start)
+		}
+		if i < len(head) {
+			t = TokenId
+			if head[i] == '.' {
+				t = TokenClass
+			}
+			start = i + 1
+		}
+	}
+	l.tokens = append(l.tokens, markers...)
+}
+
+// lexBooleanAttribute lexes a boolean attribute ([name]) and returns if it
+// succeeded or not.
+func (l *lexer) lexBooleanAttribute() bool {
+	end := strings.IndexByte(l.s[l.pos:], ']')
+	if end < 0 || l.pos+end+1 < len(l.s) && !space(l.s[l.pos+end+1]) {
+		return false
+	}
+	l.emit(TokenBooleanAttribute, l.s[l.pos+1:l.pos+end], l.pos)
+	l.pos += end + 1
+	return true
+}
+
+// lexAttribute lexes an attribute name and its value. A value can be
+// enclosed by double quotes or single quotes and an unquoted value can have
+// actions which have spaces.
+func (l *lexer) lexAttribute() {
+	name := attributeName.FindString(l.s[l.pos:])
+	l.emit(TokenAttributeName, name[:len(name)-1], l.pos)
+	l.pos += len(name)
+	start := l.pos
+	if l.pos < len(l.s) && (l.s[l.pos] == '"' || l.s[l.pos] == '\'') {
+		if end := closingQuote(l.s, l.pos); end > 0 && (end+1 == len(l.s) || space(l.s[end+1])) {
+			l.pos = end + 1
+			l.emit(TokenAttributeValue, unquote(l.s[start:l.pos]), start)
+			return
+		}
+	}
+	for l.pos < len(l.s) && !space(l.s[l.pos]) {
+		if l.delimLeft != "" && strings.HasPrefix(l.s[l.pos:], l.delimLeft) {
+			if end := strings.Index(l.s[l.pos:], l.delimRight); end > 0 {
+				l.pos += end + len(l.delimRight)
+				continue
+			}
+		}
+		l.pos++
+	}
+	l.emit(TokenAttributeValue, l.s[start:l.pos], start)
+}
+
+// lexText splits the rest of the line into texts and actions.
+func (l *lexer) lexText() {
+	for l.pos < len(l.s) {
+		i := -1
+		if l.delimLeft != "" {
+			i = strings.Index(l.s[l.pos:], l.delimLeft)
+		}
+		if i < 0 {
+			break
+		}
+		j := strings.Index(l.s[l.pos+i:], l.delimRight)
+		if j < 0 {
+			break
+		}
+		if i > 0 {
+			l.emit(TokenText, l.s[l.pos:l.pos+i], l.pos)
+		}
+		l.emit(TokenAction, l.s[l.pos+i:l.pos+i+j+len(l.delimRight)], l.pos+i)
+		l.pos += i + j + len(l.delimRight)
+	}
+	if l.pos < len(l.s) {
+		l.emit(TokenText, l.s[l.pos:], l.pos)
+	}
+	l.pos = len(l.s)
+}
+
+// closingQuote returns the index of the quote which closes the quote at the
+// index or -1 if the quote is not closed. Escaped quotes are skipped.
+func closingQuote(s string, i int) int {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case s[i]:
+			return j
+		}
+	}
+	return -1
+}
+
+// space returns if the byte is a whitespace or not.
+func space(b byte) bool {
+	return b == ' ' || b == '\t'
+}
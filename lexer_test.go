@@ -0,0 +1,114 @@
+package gold
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	// When the line has a head, attributes, a boolean attribute and a text.
+	tokens := lex(`div#a.b.c<! href='x y' [on] t {{.X}} u`, 3, "{{", "}}")
+	expected := []Token{
+		{TokenTag, "div", 3},
+		{TokenId, "a", 7},
+		{TokenClass, "b", 9},
+		{TokenClass, "c", 11},
+		{TokenMarker, "<", 12},
+		{TokenMarker, "!", 13},
+		{TokenAttributeName, "href", 15},
+		{TokenAttributeValue, "x y", 20},
+		{TokenBooleanAttribute, "on", 26},
+		{TokenText, "t ", 31},
+		{TokenAction, "{{.X}}", 33},
+		{TokenText, " u", 39},
+	}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Tokens should be %v. [actual: %v]", expected, tokens)
+	}
+
+	// When the text has an equal sign.
+	tokens = lex("p 1+1=2", 1, "{{", "}}")
+	expected = []Token{{TokenTag, "p", 1}, {TokenText, "1+1=2", 3}}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Tokens should be %v. [actual: %v]", expected, tokens)
+	}
+
+	// When the text starts explicitly.
+	tokens = lex("p  |  a=b", 1, "{{", "}}")
+	expected = []Token{{TokenTag, "p", 1}, {TokenText, " a=b", 6}}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Tokens should be %v. [actual: %v]", expected, tokens)
+	}
+
+	// When the value has an action which has spaces.
+	tokens = lex(`.a.  title="say \"hi\"" href={{.A | f}}`, 1, "{{", "}}")
+	expected = []Token{
+		{TokenClass, "a", 2},
+		{TokenMarker, ".", 3},
+		{TokenAttributeName, "title", 6},
+		{TokenAttributeValue, `say "hi"`, 12},
+		{TokenAttributeName, "href", 25},
+		{TokenAttributeValue, "{{.A | f}}", 30},
+	}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Tokens should be %v. [actual: %v]", expected, tokens)
+	}
+
+	// When the quote and the bracket are not closed.
+	tokens = lex(`p a="b c [d e`, 1, "{{", "}}")
+	expected = []Token{{TokenTag, "p", 1}, {TokenAttributeName, "a", 3}, {TokenAttributeValue, `"b`, 5}, {TokenText, "c [d e", 8}}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Tokens should be %v. [actual: %v]", expected, tokens)
+	}
+
+	// When the head is javascript:.
+	tokens = lex("javascript:", 1, "{{", "}}")
+	expected = []Token{{TokenTag, "javascript:", 1}}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Tokens should be %v. [actual: %v]", expected, tokens)
+	}
+}
+
+func TestTokenTypeString(t *testing.T) {
+	if s := TokenBooleanAttribute.String(); s != "booleanAttribute" {
+		t.Errorf("Return string should be %s. [actual: %s]", "booleanAttribute", s)
+	}
+}
+
+func TestClosingQuote(t *testing.T) {
+	// When the quote is closed.
+	if i := closingQuote(`a="b\"c"d`, 2); i != 7 {
+		t.Errorf("Returned value should be %d. [actual: %d]", 7, i)
+	}
+
+	// When the quote is not closed.
+	if i := closingQuote(`a='b`, 2); i != -1 {
+		t.Errorf("Returned value should be %d. [actual: %d]", -1, i)
+	}
+}
+
+func TestWords(t *testing.T) {
+	cases := map[string][]string{
+		// When a pair of double quotes exists.
+		`div attr="val1 val2" AAA`: {"div", `attr="val1 val2"`, "AAA"},
+		// When a double quote is not closed.
+		`div "AAA BBB`: {"div", `"AAA`, "BBB"},
+		// When escaped double quotes exist.
+		`div title="say \"hi there\"" AAA`: {"div", `title="say \"hi there\""`, "AAA"},
+		// When a pair of single quotes exists in an attribute.
+		`div title='it\'s a b' AAA`: {"div", `title='it\'s a b'`, "AAA"},
+		// When the words are separated by multiple whitespaces.
+		"div  a \t[b c]": {"div", "a", "[b c]"},
+		// When a single quote exists in a text.
+		`div don't stop`: {"div", "don't", "stop"},
+		// When an action has whitespaces.
+		`include card title={{.A "b c"}} {{.D E}}`: {"include", "card", `title={{.A "b c"}}`, "{{.D E}}"},
+		// When the line is blank.
+		" ": {""},
+	}
+	for s, expected := range cases {
+		if w := words(s, "{{", "}}"); !reflect.DeepEqual(w, expected) {
+			t.Errorf("Words should be %q. [actual: %q]", expected, w)
+		}
+	}
+}
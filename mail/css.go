@@ -0,0 +1,166 @@
+package mail
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	styleElements = regexp.MustCompile(`(?is)<style\b[^>]*>(.*?)</style>`)
+	cssComments   = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssRules      = regexp.MustCompile(`([^{}]+)\{([^{}]*)\}`)
+	selectors     = regexp.MustCompile(`^([a-zA-Z][\w-]*)?(#[\w-]+)?((?:\.[\w-]+)*)$`)
+	startTags     = regexp.MustCompile(`<([a-zA-Z][\w:-]*)((?:\s+[^\s=>/]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s>]+))?)*)\s*(/?)>`)
+	classAttr     = regexp.MustCompile(`\sclass="([^"]*)"`)
+	idAttr        = regexp.MustCompile(`\sid="([^"]*)"`)
+	styleAttr     = regexp.MustCompile(`\sstyle="([^"]*)"`)
+)
+
+// A rule represents a CSS rule whose selector is a simple selector.
+type rule struct {
+	tag          string
+	id           string
+	classes      []string
+	declarations string
+	specificity  int
+}
+
+// matches returns if the rule's selector matches the element or not.
+func (r *rule) matches(tag, id string, classes []string) bool {
+	if r.tag != "" && !strings.EqualFold(r.tag, tag) || r.id != "" && r.id != id {
+		return false
+	}
+	for _, c := range r.classes {
+		if !contains(classes, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// InlineCSS moves the CSS rules of the style elements into the style
+// attributes of the elements which the rules' selectors match. Type, class
+// and id selectors and their compounds are supported. The rules whose
+// selectors are not supported, such as descendant selectors and
+// pseudo-classes, are kept in the style elements. The style elements which
+// have at-rules such as media queries are kept as they are.
+func InlineCSS(html string) string {
+	var rules []*rule
+	html = styleElements.ReplaceAllStringFunc(html, func(m string) string {
+		css := cssComments.ReplaceAllString(styleElements.FindStringSubmatch(m)[1], "")
+		if strings.Contains(css, "@") {
+			return m
+		}
+		inlined, residual := parseRules(css)
+		rules = append(rules, inlined...)
+		if residual == "" {
+			return ""
+		}
+		return m[:strings.Index(m, ">")+1] + residual + "</style>"
+	})
+	if len(rules) == 0 {
+		return html
+	}
+	sort.Stable(bySpecificity(rules))
+	return startTags.ReplaceAllStringFunc(html, func(m string) string {
+		sm := startTags.FindStringSubmatch(m)
+		tag, attrs := sm[1], sm[2]
+		var id string
+		if am := idAttr.FindStringSubmatch(attrs); am != nil {
+			id = am[1]
+		}
+		var classes []string
+		if am := classAttr.FindStringSubmatch(attrs); am != nil {
+			classes = strings.Fields(am[1])
+		}
+		var declarations []string
+		for _, r := range rules {
+			if r.matches(tag, id, classes) {
+				declarations = append(declarations, r.declarations)
+			}
+		}
+		if len(declarations) == 0 {
+			return m
+		}
+		if am := styleAttr.FindStringSubmatch(attrs); am != nil {
+			declarations = append(declarations, strings.TrimSuffix(strings.TrimSpace(am[1]), ";"))
+			attrs = styleAttr.ReplaceAllString(attrs, "")
+		}
+		closing := ">"
+		if sm[3] != "" {
+			closing = " />"
+		}
+		return "<" + tag + attrs + ` style="` + strings.Join(declarations, "; ") + `"` + closing
+	})
+}
+
+// parseRules parses the CSS source codes and returns the rules whose
+// selectors are simple selectors and the CSS source codes of the other
+// rules.
+func parseRules(css string) ([]*rule, string) {
+	var rules []*rule
+	var residual []string
+	for _, m := range cssRules.FindAllStringSubmatch(css, -1) {
+		declarations := normalizeDeclarations(m[2])
+		if declarations == "" {
+			continue
+		}
+		var others []string
+		for _, selector := range strings.Split(m[1], ",") {
+			selector = strings.TrimSpace(selector)
+			sm := selectors.FindStringSubmatch(selector)
+			if sm == nil || sm[0] == "" {
+				if selector != "" {
+					others = append(others, selector)
+				}
+				continue
+			}
+			r := &rule{tag: sm[1], id: strings.TrimPrefix(sm[2], "#"), declarations: declarations}
+			if sm[3] != "" {
+				r.classes = strings.Split(strings.TrimPrefix(sm[3], "."), ".")
+			}
+			if r.tag != "" {
+				r.specificity++
+			}
+			r.specificity += len(r.classes) * 10
+			if r.id != "" {
+				r.specificity += 100
+			}
+			rules = append(rules, r)
+		}
+		if len(others) > 0 {
+			residual = append(residual, strings.Join(others, ", ")+" { "+declarations+" }")
+		}
+	}
+	return rules, strings.Join(residual, " ")
+}
+
+// normalizeDeclarations trims the declarations and joins them with
+// semicolons.
+func normalizeDeclarations(s string) string {
+	var declarations []string
+	for _, d := range strings.Split(s, ";") {
+		if d = strings.Join(strings.Fields(d), " "); d != "" {
+			declarations = append(declarations, d)
+		}
+	}
+	return strings.Join(declarations, "; ")
+}
+
+// contains returns if the strings contain the string or not.
+func contains(ss []string, s string) bool {
+	for _, t := range ss {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+// bySpecificity sorts the rules by their specificities.
+type bySpecificity []*rule
+
+func (rs bySpecificity) Len() int           { return len(rs) }
+func (rs bySpecificity) Less(i, j int) bool { return rs[i].specificity < rs[j].specificity }
+func (rs bySpecificity) Swap(i, j int)      { rs[i], rs[j] = rs[j], rs[i] }
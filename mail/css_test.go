@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"testing"
+)
+
+func TestInlineCSS(t *testing.T) {
+	html := `<style>/* comment */ p { color: red } #main.box, em { margin: 0 } .box { color: blue; }</style><p>a</p><div id="main" class="box" style="padding: 1px">b</div><img src="c" />`
+	expectedString := `<p style="color: red">a</p><div id="main" class="box" style="color: blue; margin: 0; padding: 1px">b</div><img src="c" />`
+	if s := InlineCSS(html); s != expectedString {
+		t.Errorf("Return string should be %s. [actual: %s]", expectedString, s)
+	}
+
+	// When the style element has rules which cannot be inlined.
+	html = `<style type="text/css">.footer a { color: red } a:hover, p { color: blue } p { margin: 0 }</style><p>a</p>`
+	expectedString = `<style type="text/css">.footer a { color: red } a:hover { color: blue }</style><p style="color: blue; margin: 0">a</p>`
+	if s := InlineCSS(html); s != expectedString {
+		t.Errorf("Return string should be %s. [actual: %s]", expectedString, s)
+	}
+
+	// When the style element has at-rules.
+	html = `<style>@media (max-width: 600px) { p { color: red } }</style><p>a</p>`
+	if s := InlineCSS(html); s != html {
+		t.Errorf("Return string should be %s. [actual: %s]", html, s)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, residual := parseRules("a.b.c, div > p, #d { color: red ; } e {}")
+	if residual != "div > p { color: red }" {
+		t.Errorf("The residual CSS should be %s. [actual: %s]", "div > p { color: red }", residual)
+	}
+	if len(rules) != 2 {
+		t.Errorf("The number of the rules should be %d. [actual: %d]", 2, len(rules))
+		return
+	}
+	if r := rules[0]; r.tag != "a" || len(r.classes) != 2 || r.specificity != 21 || r.declarations != "color: red" {
+		t.Errorf("The rule is invalid. [actual: %+v]", r)
+	}
+	if r := rules[1]; r.id != "d" || r.specificity != 100 {
+		t.Errorf("The rule is invalid. [actual: %+v]", r)
+	}
+}
@@ -0,0 +1,76 @@
+// Package mail renders multipart emails from Gold templates. One Gold
+// template generates both of an HTML body and a plain text body.
+package mail
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yosssi/gold"
+)
+
+var (
+	spaces    = regexp.MustCompile(`[ \t]+`)
+	linefeeds = regexp.MustCompile(`\n{3,}`)
+)
+
+// A Message represents the bodies of a multipart email.
+type Message struct {
+	HTML string
+	Text string
+}
+
+// Render renders the Gold template file and returns a message. The CSS of
+// the style elements is inlined into the HTML body and the text body is
+// derived from the same template.
+func Render(g *gold.Generator, path string, data interface{}) (*Message, error) {
+	htmlTpl, err := g.CompileBackend(gold.HTMLBackend, path)
+	if err != nil {
+		return nil, err
+	}
+	textTpl, err := g.CompileBackend(gold.PlainTextBackend, path)
+	if err != nil {
+		return nil, err
+	}
+	return render(htmlTpl, textTpl, data)
+}
+
+// RenderString renders the Gold template string and returns a message.
+func RenderString(g *gold.Generator, stringTemplates map[string]string, name string, data interface{}) (*Message, error) {
+	htmlTpl, err := g.CompileStringBackend(gold.HTMLBackend, stringTemplates, name)
+	if err != nil {
+		return nil, err
+	}
+	textTpl, err := g.CompileStringBackend(gold.PlainTextBackend, stringTemplates, name)
+	if err != nil {
+		return nil, err
+	}
+	return render(htmlTpl, textTpl, data)
+}
+
+// render executes the templates and returns a message.
+func render(htmlTpl, textTpl gold.Executor, data interface{}) (*Message, error) {
+	var htmlBf, textBf bytes.Buffer
+	if err := htmlTpl.Execute(&htmlBf, data); err != nil {
+		return nil, err
+	}
+	if err := textTpl.Execute(&textBf, data); err != nil {
+		return nil, err
+	}
+	return &Message{HTML: InlineCSS(htmlBf.String()), Text: tidy(textBf.String())}, nil
+}
+
+// tidy collapses the spaces and the empty lines of the text. The indents of
+// the bullets are kept.
+func tidy(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		text := strings.TrimSpace(spaces.ReplaceAllString(line, " "))
+		if strings.HasPrefix(text, "* ") {
+			text = line[:len(line)-len(strings.TrimLeft(line, " "))] + text
+		}
+		lines[i] = text
+	}
+	return strings.TrimSpace(linefeeds.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")) + "\n"
+}
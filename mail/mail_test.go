@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/yosssi/gold"
+)
+
+func TestRender(t *testing.T) {
+	g := gold.NewGenerator(false)
+	m, err := Render(g, "./test/TestRender/001.gold", map[string]interface{}{"Name": "Tom", "URL": "http://example.com/confirm"})
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<!DOCTYPE html><html><head></head><body><p>Hi Tom,</p><a class="button" href="http://example.com/confirm" style="color: #fff; background: #06c">Confirm</a></body></html>`
+	if m.HTML != expectedString {
+		t.Errorf("HTML should be %s. [actual: %s]", expectedString, m.HTML)
+	}
+	expectedString = "Hi Tom,\n\nConfirm [1]\n\n[1] http://example.com/confirm\n"
+	if m.Text != expectedString {
+		t.Errorf("Text should be %q. [actual: %q]", expectedString, m.Text)
+	}
+
+	// When the file does not exist.
+	if _, err := Render(g, "./test/TestRender/none.gold", nil); err == nil {
+		t.Errorf("An error should be returned.")
+	}
+}
+
+func TestRenderString(t *testing.T) {
+	src := `
+doctype html
+html
+  head
+    title Welcome
+  body
+    h1 Hello {{.Name}}
+    p
+      | Thanks for joining.
+      a href={{.URL}} Visit us
+      | today.
+    ul
+      each item in .Items
+        li {{$item}}
+          ul
+            li nested
+    hr
+    p Bye
+`
+	data := map[string]interface{}{"Name": "<Tom>", "URL": "http://example.com/?a=1&b=2", "Items": []string{"a"}}
+	m, err := RenderString(gold.NewGenerator(false), map[string]string{"src": src}, "src", data)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "Hello <Tom>\n\nThanks for joining. Visit us [1] today.\n\n* a\n  * nested\n\n----------\n\nBye\n\n[1] http://example.com/?a=1&b=2\n"
+	if m.Text != expectedString {
+		t.Errorf("Text should be %q. [actual: %q]", expectedString, m.Text)
+	}
+
+	// When the template is invalid.
+	if _, err := RenderString(gold.NewGenerator(false), map[string]string{"src": "block"}, "src", nil); err == nil {
+		t.Errorf("An error should be returned.")
+	}
+
+	// When the execution returns an error.
+	if _, err := RenderString(gold.NewGenerator(false), map[string]string{"src": "p {{.Name.Last}}"}, "src", map[string]string{"Name": "a"}); err == nil {
+		t.Errorf("An error should be returned.")
+	}
+}
+
+func TestTidy(t *testing.T) {
+	expectedString := "a b\n\n  * c\n"
+	if s := tidy("  a   b \n\n\n\n  * c  \n\n"); s != expectedString {
+		t.Errorf("Return string should be %q. [actual: %q]", expectedString, s)
+	}
+}
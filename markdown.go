@@ -0,0 +1,140 @@
+package gold
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	mdHeading     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mdRule        = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+	mdUnordered   = regexp.MustCompile(`^[*+-]\s+(.*)$`)
+	mdOrdered     = regexp.MustCompile(`^\d+\.\s+(.*)$`)
+	mdCodeSpan    = regexp.MustCompile("`([^`]+)`")
+	mdImage       = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	mdLink        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdStrong      = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdEmphasis    = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	mdPlaceholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// safeURLSchemes holds the URL schemes which Markdown links and images can
+// have.
+var safeURLSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// markdownFilter returns a filter which converts the Markdown source codes
+// to HTML. It supports headings, paragraphs, lists, blockquotes, code blocks,
+// horizontal rules, code spans, links, images, strong emphases and emphases.
+// Actions enclosed by the delimiters are kept intact.
+func markdownFilter(delimLeft, delimRight string) Filter {
+	return func(b []byte) ([]byte, error) {
+		var bf bytes.Buffer
+		writeMarkdownBlocks(&bf, strings.Split(formatLf(string(b)), "\n"), delimLeft, delimRight)
+		return bf.Bytes(), nil
+	}
+}
+
+// writeMarkdownBlocks writes the HTML of the Markdown block elements to the
+// buffer.
+func writeMarkdownBlocks(bf *bytes.Buffer, lines []string, delimLeft, delimRight string) {
+	inline := func(s string) string {
+		return markdownInline(s, delimLeft, delimRight)
+	}
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			bf.WriteString("<p>" + inline(strings.Join(paragraph, "\n")) + "</p>")
+			paragraph = nil
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			bf.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+		case mdHeading.MatchString(line):
+			flush()
+			m := mdHeading.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			bf.WriteString("<h" + level + ">" + inline(m[2]) + "</h" + level + ">")
+		case mdRule.MatchString(line):
+			flush()
+			bf.WriteString("<hr>")
+		case strings.HasPrefix(line, ">"):
+			flush()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			i--
+			bf.WriteString("<blockquote>")
+			writeMarkdownBlocks(bf, quoted, delimLeft, delimRight)
+			bf.WriteString("</blockquote>")
+		case mdUnordered.MatchString(line) || mdOrdered.MatchString(line):
+			flush()
+			re, tag := mdUnordered, "ul"
+			if mdOrdered.MatchString(line) {
+				re, tag = mdOrdered, "ol"
+			}
+			bf.WriteString("<" + tag + ">")
+			for ; i < len(lines) && re.MatchString(strings.TrimSpace(lines[i])); i++ {
+				bf.WriteString("<li>" + inline(re.FindStringSubmatch(strings.TrimSpace(lines[i]))[1]) + "</li>")
+			}
+			i--
+			bf.WriteString("</" + tag + ">")
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+}
+
+// markdownInline converts the Markdown inline elements to HTML. Actions
+// enclosed by the delimiters are kept intact.
+func markdownInline(s, delimLeft, delimRight string) string {
+	var protected []string
+	protect := func(h string) string {
+		protected = append(protected, h)
+		return "\x00" + strconv.Itoa(len(protected)-1) + "\x00"
+	}
+	s = mdCodeSpan.ReplaceAllStringFunc(s, func(m string) string {
+		return protect("<code>" + html.EscapeString(mdCodeSpan.FindStringSubmatch(m)[1]) + "</code>")
+	})
+	s = mapText(s, delimLeft, delimRight, func(t string) string {
+		t = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(t)
+		t = mdImage.ReplaceAllStringFunc(t, func(m string) string {
+			sm := mdImage.FindStringSubmatch(m)
+			return `<img src="` + markdownURL(sm[2]) + `" alt="` + strings.Replace(sm[1], `"`, "&#34;", -1) + `">`
+		})
+		t = mdLink.ReplaceAllStringFunc(t, func(m string) string {
+			sm := mdLink.FindStringSubmatch(m)
+			return `<a href="` + markdownURL(sm[2]) + `">` + sm[1] + "</a>"
+		})
+		t = mdStrong.ReplaceAllString(t, "<strong>$1$2</strong>")
+		return mdEmphasis.ReplaceAllString(t, "<em>$1$2</em>")
+	})
+	return mdPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		i, _ := strconv.Atoi(strings.Trim(m, "\x00"))
+		return protected[i]
+	})
+}
+
+// markdownURL returns the URL of a Markdown link or image as an attribute
+// value. A URL whose scheme is not safe is replaced with "#ZgotmplZ" as the
+// html/template package does.
+func markdownURL(u string) string {
+	if i := strings.IndexAny(u, ":/?#"); i > -1 && u[i] == ':' && !safeURLSchemes[strings.ToLower(u[:i])] {
+		return "#ZgotmplZ"
+	}
+	return strings.Replace(u, `"`, "&#34;", -1)
+}
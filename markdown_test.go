@@ -0,0 +1,41 @@
+package gold
+
+import (
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	src := "# Gold {{.Version}}\n\nGold is a **template** engine for [Go](http://golang.org/).\nUse `a < b`.\n\n- one\n- _two_\n\n1. first\n\n> quoted\n\n```\nx := <-c\n```\n---"
+	b, err := markdownFilter("{{", "}}")([]byte(src))
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := `<h1>Gold {{.Version}}</h1>` +
+		`<p>Gold is a <strong>template</strong> engine for <a href="http://golang.org/">Go</a>.` + "\n" + `Use <code>a &lt; b</code>.</p>` +
+		`<ul><li>one</li><li><em>two</em></li></ul>` +
+		`<ol><li>first</li></ol>` +
+		`<blockquote><p>quoted</p></blockquote>` +
+		`<pre><code>x := &lt;-c</code></pre>` +
+		`<hr>`
+	if string(b) != expectedString {
+		t.Errorf("Returned value should be %s [actual: %s]", expectedString, string(b))
+	}
+}
+
+func TestMarkdownInline(t *testing.T) {
+	cases := map[string]string{
+		// When the URLs have double quotes.
+		`[x](a"onclick="y) ![a"b](c"d)`: `<a href="a&#34;onclick=&#34;y">x</a> <img src="c&#34;d" alt="a&#34;b">`,
+		// When the URLs have unsafe schemes.
+		`[x](javascript:alert(1) [y](JavaScript:z) ![z](data:image/png)`: `<a href="#ZgotmplZ">x</a> <a href="#ZgotmplZ">y</a> <img src="#ZgotmplZ" alt="z">`,
+		// When the URLs have safe schemes or no schemes.
+		`[a](https://a.b/?c=d:e) [b](mailto:a@b) [c](/d:e)`: `<a href="https://a.b/?c=d:e">a</a> <a href="mailto:a@b">b</a> <a href="/d:e">c</a>`,
+		// When the actions are enclosed by the custom delimiters.
+		`**a** <%.B "**c**"%> {{**d**}}`: `<strong>a</strong> <%.B "**c**"%> {{<strong>d</strong>}}`,
+	}
+	for s, expectedString := range cases {
+		if h := markdownInline(s, "<%", "%>"); h != expectedString {
+			t.Errorf("Returned value should be %s [actual: %s]", expectedString, h)
+		}
+	}
+}
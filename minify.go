@@ -0,0 +1,74 @@
+package gold
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	whitespaces     = regexp.MustCompile(`\s+`)
+	cssComments     = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssPunctuations = regexp.MustCompile(`\s*([{};,>])\s*`)
+)
+
+// preformattedTags holds the tags whose whitespaces are significant.
+var preformattedTags = map[string]bool{"pre": true, "textarea": true, "code": true}
+
+// mapText applies the function to the parts of the string which are not
+// enclosed by the delimiters and returns the result string.
+func mapText(s, delimLeft, delimRight string, f func(string) string) string {
+	if delimLeft == "" || delimRight == "" {
+		return f(s)
+	}
+	var parts []string
+	for {
+		i := strings.Index(s, delimLeft)
+		if i < 0 {
+			break
+		}
+		j := strings.Index(s[i+len(delimLeft):], delimRight)
+		if j < 0 {
+			break
+		}
+		j += i + len(delimLeft) + len(delimRight)
+		parts = append(parts, f(s[:i]), s[i:j])
+		s = s[j:]
+	}
+	return strings.Join(append(parts, f(s)), "")
+}
+
+// collapseWhitespace replaces the sequences of whitespaces which are not
+// enclosed by the delimiters with a space.
+func collapseWhitespace(s, delimLeft, delimRight string) string {
+	return mapText(s, delimLeft, delimRight, func(t string) string {
+		return whitespaces.ReplaceAllString(t, " ")
+	})
+}
+
+// minifyCSS removes the comments and the insignificant whitespaces from the
+// CSS source codes.
+func minifyCSS(s, delimLeft, delimRight string) string {
+	return strings.TrimSpace(mapText(s, delimLeft, delimRight, func(t string) string {
+		t = cssComments.ReplaceAllString(t, "")
+		t = whitespaces.ReplaceAllString(t, " ")
+		return cssPunctuations.ReplaceAllString(t, "$1")
+	}))
+}
+
+// minifyJS removes the indents and the empty lines from the JavaScript
+// source codes. Line feeds are kept for the automatic semicolon insertion.
+func minifyJS(lines []string) string {
+	var minified []string
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			minified = append(minified, line)
+		}
+	}
+	return strings.Join(minified, "\n")
+}
+
+// unquotable returns if the attribute value can be written without quotes
+// or not.
+func unquotable(v, delimLeft string) bool {
+	return v != "" && !strings.ContainsAny(v, " \t\n\r\f\"'=<>`") && (delimLeft == "" || !strings.Contains(v, delimLeft))
+}
@@ -0,0 +1,54 @@
+package gold
+
+import (
+	"testing"
+)
+
+func TestMapText(t *testing.T) {
+	// When the string has actions.
+	s := mapText("a{{b}}c{{d", "{{", "}}", func(t string) string { return "[" + t + "]" })
+	if s != "[a]{{b}}[c{{d]" {
+		t.Errorf("Returned value is invalid. [actual: %s]", s)
+	}
+
+	// When the delimiters are empty.
+	s = mapText("a{{b}}", "", "", func(t string) string { return "[" + t + "]" })
+	if s != "[a{{b}}]" {
+		t.Errorf("Returned value is invalid. [actual: %s]", s)
+	}
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	if s := collapseWhitespace("a  \n b {{\"c  d\"}}", "{{", "}}"); s != `a b {{"c  d"}}` {
+		t.Errorf("Returned value is invalid. [actual: %s]", s)
+	}
+}
+
+func TestMinifyCSS(t *testing.T) {
+	if s := minifyCSS("/* comment */\na , b {\n  color: red;\n}\n", "{{", "}}"); s != "a,b{color: red;}" {
+		t.Errorf("Returned value is invalid. [actual: %s]", s)
+	}
+}
+
+func TestMinifyJS(t *testing.T) {
+	if s := minifyJS([]string{"  var a = 1;", "", "  alert(a);"}); s != "var a = 1;\nalert(a);" {
+		t.Errorf("Returned value is invalid. [actual: %s]", s)
+	}
+}
+
+func TestUnquotable(t *testing.T) {
+	// When the value can be unquoted.
+	if unquotable("main", "{{") != true {
+		t.Errorf("Returned value should be true.")
+	}
+
+	// When the value has a space.
+	if unquotable("a b", "{{") != false {
+		t.Errorf("Returned value should be false.")
+	}
+
+	// When the value has an action.
+	if unquotable("{{.URL}}", "{{") != false {
+		t.Errorf("Returned value should be false.")
+	}
+}
@@ -0,0 +1,216 @@
+package gold
+
+import (
+	"sort"
+)
+
+// A NodeKind represents the kind of a node of a parsed Gold template.
+type NodeKind int
+
+// Node kinds. KindUnknown is the kind of an element whose type is unknown.
+const (
+	KindUnknown NodeKind = iota
+	KindTemplate
+	KindBlock
+	KindTag
+	KindText
+	KindContent
+	KindExpression
+	KindOutputExpression
+	KindComment
+	KindBlockElement
+	KindInclude
+	KindDefine
+	KindTemplateCall
+	KindIf
+	KindElse
+	KindRange
+	KindWith
+	KindEach
+	KindCase
+	KindWhen
+	KindDefault
+	KindFilter
+	KindSuper
+)
+
+// nodeKindNames holds the names of the node kinds.
+var nodeKindNames = map[NodeKind]string{
+	KindUnknown:          "unknown",
+	KindTemplate:         "template",
+	KindBlock:            "block",
+	KindTag:              "tag",
+	KindText:             "text",
+	KindContent:          "content",
+	KindExpression:       "expression",
+	KindOutputExpression: "outputExpression",
+	KindComment:          "comment",
+	KindBlockElement:     "blockElement",
+	KindInclude:          "include",
+	KindDefine:           "define",
+	KindTemplateCall:     "templateCall",
+	KindIf:               "if",
+	KindElse:             "else",
+	KindRange:            "range",
+	KindWith:             "with",
+	KindEach:             "each",
+	KindCase:             "case",
+	KindWhen:             "when",
+	KindDefault:          "default",
+	KindFilter:           "filter",
+	KindSuper:            "super",
+}
+
+// elementKinds holds the node kinds of the element types.
+var elementKinds = map[string]NodeKind{
+	TypeTag:              KindTag,
+	TypeLiteral:          KindText,
+	TypeContent:          KindContent,
+	TypeExpression:       KindExpression,
+	TypeOutputExpression: KindOutputExpression,
+	TypeBlock:            KindBlockElement,
+	TypeInclude:          KindInclude,
+	TypeDefine:           KindDefine,
+	TypeTemplate:         KindTemplateCall,
+	TypeIf:               KindIf,
+	TypeElse:             KindElse,
+	TypeRange:            KindRange,
+	TypeWith:             KindWith,
+	TypeEach:             KindEach,
+	TypeCase:             KindCase,
+	TypeWhen:             KindWhen,
+	TypeDefault:          KindDefault,
+	TypeFilter:           KindFilter,
+	TypeSuper:            KindSuper,
+}
+
+// String returns the name of the node kind.
+func (k NodeKind) String() string {
+	return nodeKindNames[k]
+}
+
+// A Node represents a node of a parsed Gold template. *Template, *Block and
+// *Element implement it.
+type Node interface {
+	// Kind returns the kind of the node.
+	Kind() NodeKind
+	// Nodes returns the child nodes of the node in the source order.
+	Nodes() []Node
+}
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children of
+// node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses a parsed Gold template in depth-first order: It starts by
+// calling v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the non-nil children of node, followed by a call of w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, child := range node.Nodes() {
+		Walk(v, child)
+	}
+	v.Visit(nil)
+}
+
+// inspector is a visitor which calls a function.
+type inspector func(Node) bool
+
+// Visit calls the function and returns the inspector if the function returns
+// true.
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a parsed Gold template in depth-first order: It starts
+// by calling f(node); node must not be nil. If f returns true, Inspect
+// invokes f recursively for each of the children of node, followed by a call
+// of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Kind returns KindTemplate.
+func (t *Template) Kind() NodeKind {
+	return KindTemplate
+}
+
+// Nodes returns the template's top elements and blocks in the order of
+// their line numbers. Blocks which have the same line number are sorted by
+// their names. The super template is not included.
+func (t *Template) Nodes() []Node {
+	nodes := elementNodes(t.Elements)
+	names := make([]string, 0, len(t.Blocks))
+	for name := range t.Blocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		nodes = append(nodes, t.Blocks[name])
+	}
+	sort.Stable(byLineNo(nodes))
+	return nodes
+}
+
+// byLineNo sorts the nodes by their line numbers.
+type byLineNo []Node
+
+func (ns byLineNo) Len() int           { return len(ns) }
+func (ns byLineNo) Less(i, j int) bool { return lineNo(ns[i]) < lineNo(ns[j]) }
+func (ns byLineNo) Swap(i, j int)      { ns[i], ns[j] = ns[j], ns[i] }
+
+// lineNo returns the line number of the node.
+func lineNo(n Node) int {
+	switch n := n.(type) {
+	case *Element:
+		return n.LineNo
+	case *Block:
+		return n.LineNo
+	}
+	return 0
+}
+
+// Kind returns KindBlock.
+func (b *Block) Kind() NodeKind {
+	return KindBlock
+}
+
+// Nodes returns the block's elements.
+func (b *Block) Nodes() []Node {
+	return elementNodes(b.Elements)
+}
+
+// Kind returns the kind of the element. KindUnknown is returned if the
+// element's type is unknown.
+func (e *Element) Kind() NodeKind {
+	if e.comment() && e.Type != TypeContent {
+		return KindComment
+	}
+	if kind, prs := elementKinds[e.Type]; prs {
+		return kind
+	}
+	return KindUnknown
+}
+
+// Nodes returns the element's children.
+func (e *Element) Nodes() []Node {
+	return elementNodes(e.Children)
+}
+
+// elementNodes converts the elements to nodes.
+func elementNodes(elements []*Element) []Node {
+	nodes := make([]Node, len(elements))
+	for i, e := range elements {
+		nodes[i] = e
+	}
+	return nodes
+}
@@ -0,0 +1,108 @@
+package gold
+
+import (
+	"reflect"
+	"testing"
+)
+
+// kindCollector collects the kinds of the visited nodes.
+type kindCollector struct {
+	kinds []NodeKind
+	ends  int
+}
+
+func (c *kindCollector) Visit(node Node) Visitor {
+	if node == nil {
+		c.ends++
+		return nil
+	}
+	c.kinds = append(c.kinds, node.Kind())
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	g := NewGenerator(false)
+	stringTemplates := map[string]string{
+		"base": "html\n  block content",
+		"page": `
+extends base
+block title
+  | Title
+block content
+  // comment
+  if .A
+    p a
+  else
+    = .B
+  each item in .Items
+    {{$item}}
+`,
+	}
+	tpl, err := g.ParseTreeString(stringTemplates, "page")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	c := &kindCollector{}
+	Walk(c, tpl)
+	expected := []NodeKind{KindTemplate, KindBlock, KindText, KindBlock, KindComment, KindIf, KindTag, KindElse, KindOutputExpression, KindEach, KindExpression}
+	if !reflect.DeepEqual(c.kinds, expected) {
+		t.Errorf("Kinds should be %v. [actual: %v]", expected, c.kinds)
+	}
+	if c.ends != len(expected) {
+		t.Errorf("Visit(nil) should be called %d times. [actual: %d]", len(expected), c.ends)
+	}
+
+	// When the super template is walked.
+	c = &kindCollector{}
+	Walk(c, tpl.Super)
+	expected = []NodeKind{KindTemplate, KindTag, KindBlockElement}
+	if !reflect.DeepEqual(c.kinds, expected) {
+		t.Errorf("Kinds should be %v. [actual: %v]", expected, c.kinds)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	g := NewGenerator(false)
+	tpl, err := g.ParseTreeString(map[string]string{"src": "div\n  a href=/a\n  p\n    a href=/b\nfooter\n  a href=/c"}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var hrefs []string
+	Inspect(tpl, func(node Node) bool {
+		e, ok := node.(*Element)
+		if ok && e.Tag == "footer" {
+			return false
+		}
+		if ok && e.Tag == "a" {
+			hrefs = append(hrefs, e.Attributes["href"])
+		}
+		return true
+	})
+	if expected := []string{"/a", "/b"}; !reflect.DeepEqual(hrefs, expected) {
+		t.Errorf("Hrefs should be %v. [actual: %v]", expected, hrefs)
+	}
+}
+
+func TestNodeKindString(t *testing.T) {
+	if s := KindTemplateCall.String(); s != "templateCall" {
+		t.Errorf("Return string should be %s. [actual: %s]", "templateCall", s)
+	}
+}
+
+func TestElementKind(t *testing.T) {
+	if kind := NewTag("p").Kind(); kind != KindTag {
+		t.Errorf("Kind should be %s. [actual: %s]", KindTag, kind)
+	}
+
+	// When the element's type is unknown.
+	e := &Element{Type: "unknown"}
+	if kind := e.Kind(); kind != KindUnknown || kind.String() != "unknown" {
+		t.Errorf("Kind should be %s. [actual: %s]", KindUnknown, kind)
+	}
+
+	// When the element's type is empty.
+	e = &Element{}
+	if kind := e.Kind(); kind != KindUnknown {
+		t.Errorf("Kind should be %s. [actual: %s]", KindUnknown, kind)
+	}
+}
@@ -0,0 +1,164 @@
+package gold
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// paramPrefix is the prefix of a param line. It cannot be a tag so that a
+// param line is distinguished from a param element.
+const paramPrefix = "@param"
+
+// paramTypes holds the functions which check if the values are valid for
+// the parameter types.
+var paramTypes = map[string]func(string) bool{
+	"string": func(v string) bool { return true },
+	"int": func(v string) bool {
+		_, err := strconv.ParseInt(v, 10, 64)
+		return err == nil
+	},
+	"float": func(v string) bool {
+		_, err := strconv.ParseFloat(v, 64)
+		return err == nil
+	},
+	"bool": func(v string) bool {
+		_, err := strconv.ParseBool(v)
+		return err == nil
+	},
+}
+
+// paramZeroValues holds the values of the optional parameters which have no
+// default values.
+var paramZeroValues = map[string]string{"string": "", "int": "0", "float": "0", "bool": "false"}
+
+// A Param represents a parameter which a Gold template declares by a param
+// line ("@param name", "@param name?" or "@param name=default"). The name
+// can be followed by a type (":string", ":int", ":float" or ":bool"). A
+// parameter without "?" or a default value is required.
+type Param struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Default  string `json:"default,omitempty"`
+	Required bool   `json:"required"`
+	LineNo   int    `json:"line,omitempty"`
+}
+
+// source returns the param line of the parameter.
+func (p *Param) source() string {
+	src := paramPrefix + " " + p.Name
+	if p.Type != "" && p.Type != "string" {
+		src += ":" + p.Type
+	}
+	switch {
+	case p.Required:
+		return src
+	case p.Default == "":
+		return src + "?"
+	}
+	return src + "=" + attributeValueSource(p.Default)
+}
+
+// valid returns if the value is valid for the parameter's type or not.
+func (p *Param) valid(v string) bool {
+	valid, prs := paramTypes[p.Type]
+	return !prs || valid(v)
+}
+
+// defaultValue returns the value of the parameter which is not passed.
+func (p *Param) defaultValue() string {
+	if p.Default == "" {
+		return paramZeroValues[p.Type]
+	}
+	return p.Default
+}
+
+// newParam parses the param line and returns a parameter.
+func newParam(line string, lineNo int, tpl *Template) (*Param, error) {
+	delimLeft, delimRight := defaultDelimLeft, defaultDelimRight
+	if tpl.Generator != nil {
+		delimLeft, delimRight = tpl.Generator.delimLeft, tpl.Generator.delimRight
+	}
+	tokens := words(strings.TrimSpace(line), delimLeft, delimRight)
+	if len(tokens) != 2 {
+		return nil, fmt.Errorf("the param line is invalid. (line no: %d, template: %s, line: %s)", lineNo, tpl.Path, strings.TrimSpace(line))
+	}
+	p := &Param{Type: "string", Required: true, LineNo: lineNo}
+	decl := tokens[1]
+	if k, v, ok := splitParameter(decl); ok {
+		decl, p.Default, p.Required = k, v, false
+	} else if strings.HasSuffix(decl, "?") {
+		decl, p.Required = strings.TrimSuffix(decl, "?"), false
+	}
+	p.Name = decl
+	if i := strings.Index(decl, ":"); i > -1 {
+		p.Name, p.Type = decl[:i], decl[i+1:]
+	}
+	if p.Name == "" || strings.HasSuffix(p.Name, "?") {
+		return nil, fmt.Errorf("the param line is invalid. (line no: %d, template: %s, line: %s)", lineNo, tpl.Path, strings.TrimSpace(line))
+	}
+	if _, prs := paramTypes[p.Type]; !prs {
+		return nil, fmt.Errorf("the param type %s is not supported. (line no: %d, template: %s)", p.Type, lineNo, tpl.Path)
+	}
+	if !p.Required && p.Default != "" && !p.valid(p.Default) {
+		return nil, fmt.Errorf("the default value %s of the param %s is not a valid %s. (line no: %d, template: %s)", p.Default, p.Name, p.Type, lineNo, tpl.Path)
+	}
+	if tpl.param(p.Name) != nil {
+		return nil, fmt.Errorf("the param %s is declared twice. (line no: %d, template: %s)", p.Name, lineNo, tpl.Path)
+	}
+	return p, nil
+}
+
+// param returns the template's parameter which has the name or nil.
+func (t *Template) param(name string) *Param {
+	for _, p := range t.Params {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// embedMap checks the parameters which the include element passes to the
+// template and returns them with the default values of the parameters which
+// are not passed. The parameters are not checked if the template declares
+// no parameters.
+func (t *Template) embedMap(embedMap EmbedMap, e *Element) (EmbedMap, error) {
+	if len(t.Params) == 0 {
+		return embedMap, nil
+	}
+	names := make([]string, 0, len(embedMap))
+	for name := range embedMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if t.param(name) == nil {
+			return nil, errors.New(fmt.Sprintf("The parameter %s is not declared by the included template %s. (line no: %d)", name, t.Path, e.LineNo))
+		}
+	}
+	params := EmbedMap{}
+	for _, p := range t.Params {
+		v, prs := embedMap[p.Name]
+		switch {
+		case prs:
+			delimLeft, _ := e.delims()
+			if !strings.Contains(v, delimLeft) && !p.valid(v) {
+				return nil, errors.New(fmt.Sprintf("The parameter %s of the included template %s is not a valid %s. [value: %s] (line no: %d)", p.Name, t.Path, p.Type, v, e.LineNo))
+			}
+			params[p.Name] = v
+		case p.Required:
+			return nil, errors.New(fmt.Sprintf("The required parameter %s of the included template %s is not given. (line no: %d)", p.Name, t.Path, e.LineNo))
+		default:
+			params[p.Name] = p.defaultValue()
+		}
+	}
+	return params, nil
+}
+
+// isParam returns if the line's prefix is "@param" or not.
+func isParam(line string) bool {
+	return strings.HasPrefix(line, paramPrefix+" ") || line == paramPrefix
+}
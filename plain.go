@@ -0,0 +1,99 @@
+package gold
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+var (
+	// plainSkippedTags holds the tags whose texts are not written as a plain
+	// text.
+	plainSkippedTags = map[string]bool{"head": true, "title": true, "style": true, "script": true, "meta": true, "link": true}
+	// plainBlockTags holds the tags whose texts are written on their own
+	// lines as a plain text.
+	plainBlockTags = map[string]bool{
+		"html": true, "body": true, "div": true, "section": true, "article": true, "header": true, "footer": true,
+		"nav": true, "main": true, "aside": true, "form": true, "ul": true, "ol": true, "dl": true, "dt": true,
+		"dd": true, "table": true, "tr": true, "pre": true, "figure": true, "address": true,
+	}
+	// plainParagraphTags holds the tags whose texts are followed by empty
+	// lines as a plain text.
+	plainParagraphTags = map[string]bool{"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "blockquote": true}
+)
+
+// writePlainText writes the text of the element as a plain text to the
+// buffer. Links are written as footnotes and list items as bullets.
+func (e *Element) writePlainText(bf *bytes.Buffer, ctx *context) error {
+	switch {
+	case e.Tag == "doctype" || plainSkippedTags[e.Tag]:
+		return nil
+	case e.Tag == "br":
+		bf.WriteString("\n")
+		return nil
+	case e.Tag == "hr":
+		bf.WriteString("\n----------\n")
+		return nil
+	case e.Tag == "img":
+		if alt := e.Attributes["alt"]; alt != "" {
+			bf.WriteString(alt + " ")
+		}
+		return nil
+	}
+	switch {
+	case e.Tag == "li":
+		bf.WriteString("\n" + strings.Repeat("  ", e.listDepth()-1) + "* ")
+	case e.Tag == "ul" || e.Tag == "ol":
+		// The list items start on their own lines.
+	case plainBlockTags[e.Tag] || plainParagraphTags[e.Tag]:
+		bf.WriteString("\n")
+	}
+	if e.hasTextValues() {
+		bf.WriteString(e.textValue())
+		if len(e.Children) > 0 {
+			bf.WriteString(" ")
+		}
+	}
+	if err := e.writeChildren(bf, ctx); err != nil {
+		return err
+	}
+	switch {
+	case e.Tag == "a" && e.Attributes["href"] != "":
+		ctx.links = append(ctx.links, e.Attributes["href"])
+		bf.WriteString(" [" + strconv.Itoa(len(ctx.links)) + "] ")
+	case e.Tag == "td" || e.Tag == "th":
+		bf.WriteString(" ")
+	case plainParagraphTags[e.Tag]:
+		bf.WriteString("\n\n")
+	case plainBlockTags[e.Tag]:
+		bf.WriteString("\n")
+	}
+	return nil
+}
+
+// listDepth returns the number of the list items which contain the element
+// including itself.
+func (e *Element) listDepth() int {
+	depth := 0
+	for p := e; p != nil; p = p.Parent {
+		if p.Tag == "li" {
+			depth++
+		}
+	}
+	return depth
+}
+
+// footnotes returns the footnotes of the links which are written as a
+// plain text.
+func (ctx *context) footnotes() string {
+	if len(ctx.links) == 0 {
+		return ""
+	}
+	var bf bytes.Buffer
+	bf.WriteString("\n")
+	for i, link := range ctx.links {
+		bf.WriteString("\n[" + strconv.Itoa(i+1) + "] " + link)
+	}
+	bf.WriteString("\n")
+	return bf.String()
+}
@@ -0,0 +1,53 @@
+package gold
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestElementWritePlainText(t *testing.T) {
+	g := NewGenerator(false)
+	src := `
+head
+  title a
+p Hello {{.Name}}
+  a href=/b b
+ul
+  li c
+br
+img alt=d
+`
+	tpl, err := g.CompileStringBackend(PlainTextBackend, map[string]string{"src": src}, "src")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	var bf bytes.Buffer
+	if err := tpl.Execute(&bf, map[string]string{"Name": "<e>"}); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "\nHello <e> b [1] \n\n\n* c\n\nd \n\n[1] /b\n"
+	if bf.String() != expectedString {
+		t.Errorf("Text should be %q. [actual: %q]", expectedString, bf.String())
+	}
+}
+
+func TestElementListDepth(t *testing.T) {
+	li := &Element{Tag: "li"}
+	ul := &Element{Tag: "ul", Parent: li}
+	e := &Element{Tag: "li", Parent: ul}
+	if depth := e.listDepth(); depth != 2 {
+		t.Errorf("Depth should be %d. [actual: %d]", 2, depth)
+	}
+}
+
+func TestContextFootnotes(t *testing.T) {
+	ctx := newContext(nil)
+	if s := ctx.footnotes(); s != "" {
+		t.Errorf("Return string should be empty. [actual: %s]", s)
+	}
+	ctx.links = []string{"a", "b"}
+	expectedString := "\n\n[1] a\n[2] b\n"
+	if s := ctx.footnotes(); s != expectedString {
+		t.Errorf("Return string should be %q. [actual: %q]", expectedString, s)
+	}
+}
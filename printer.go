@@ -0,0 +1,233 @@
+package gold
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// printIndent is the indent of a line of the printed source.
+const printIndent = "  "
+
+// Fprint writes the Gold source of the node to the writer. The source of a
+// tag element is generated from its tag, id, classes, attributes and texts
+// so that changes of them are reflected. The other elements are written as
+// their texts. Lines are indented by two spaces.
+func Fprint(w io.Writer, node Node) error {
+	var bf bytes.Buffer
+	switch n := node.(type) {
+	case *Template:
+		n.writeSource(&bf)
+	case *Block:
+		n.writeSource(&bf, indentTop)
+	case *Element:
+		n.writeSource(&bf, indentTop)
+	}
+	_, err := w.Write(bf.Bytes())
+	return err
+}
+
+// Sprint returns the Gold source of the node.
+func Sprint(node Node) string {
+	var bf bytes.Buffer
+	Fprint(&bf, node)
+	return bf.String()
+}
+
+// writeSource writes the template's source to the buffer.
+func (t *Template) writeSource(bf *bytes.Buffer) {
+	if t.Extends != "" {
+		writeSourceLine(bf, indentTop, "extends "+t.Extends)
+	}
+	for _, p := range t.Params {
+		writeSourceLine(bf, indentTop, p.source())
+	}
+	for _, node := range t.Nodes() {
+		switch n := node.(type) {
+		case *Block:
+			n.writeSource(bf, indentTop)
+		case *Element:
+			n.writeSource(bf, indentTop)
+		}
+	}
+}
+
+// writeSource writes the block's source to the buffer.
+func (b *Block) writeSource(bf *bytes.Buffer, depth int) {
+	writeSourceLine(bf, depth, "block "+b.Name)
+	for _, e := range b.Elements {
+		e.writeSource(bf, depth+1)
+	}
+}
+
+// writeSource writes the element's source to the buffer.
+func (e *Element) writeSource(bf *bytes.Buffer, depth int) {
+	if e.Type == TypeContent {
+		e.writeContentSource(bf, depth)
+		return
+	}
+	line, children := e.source()
+	writeSourceLine(bf, depth, line)
+	for _, child := range children {
+		child.writeSource(bf, depth+1)
+	}
+}
+
+// writeContentSource writes the content element's line and its descendant
+// content elements' lines to the buffer. The indents of the lines which are
+// deeper than the raw content element's children are kept.
+func (e *Element) writeContentSource(bf *bytes.Buffer, depth int) {
+	base := e.Parent
+	for base != nil && base.Type == TypeContent {
+		base = base.Parent
+	}
+	n := e.Indent
+	if base != nil {
+		n = base.Indent + 1
+	}
+	writeSourceLine(bf, depth, dedent(e.Text, n))
+	for _, child := range e.Children {
+		child.writeContentSource(bf, depth)
+	}
+}
+
+// source returns the element's source line and the children which are
+// written under the line. A tag element which nests an element by the
+// block expansion is written as "tag: nested".
+func (e *Element) source() (string, []*Element) {
+	var children []*Element
+	for _, child := range e.Children {
+		if !child.Inline {
+			children = append(children, child)
+		}
+	}
+	switch {
+	case e.Type == TypeLiteral:
+		value := e.literalValue()
+		if e.hasInlineChildren() {
+			value = e.inlineSource()
+		}
+		if value == "" {
+			return e.Tokens[0], children
+		}
+		return e.Tokens[0] + " " + value, children
+	case e.Type != TypeTag || e.comment():
+		return e.Text, children
+	}
+	head, rest := e.tagSource()
+	if e.Nested == nil || rest != "" || len(children) == 0 || children[0] != e.Nested {
+		return head + rest, children
+	}
+	line, nestedChildren := e.Nested.source()
+	return head + ": " + line, append(nestedChildren, children[1:]...)
+}
+
+// tagSource returns the tag element's head (a tag, an id, classes and
+// markers) and the rest of its source line (attributes, boolean attributes
+// and a text).
+func (e *Element) tagSource() (string, string) {
+	var head bytes.Buffer
+	tag := e.Tag
+	if tag == "" {
+		tag = "div"
+	}
+	head.WriteString(tag)
+	if e.hasId() {
+		head.WriteString("#" + e.Id)
+	}
+	for _, class := range e.Classes {
+		head.WriteString("." + class)
+	}
+	if e.RawContent && tag != "script" && tag != "style" {
+		head.WriteString(".")
+	}
+	if e.Unescaped {
+		head.WriteString("!")
+	}
+	if e.SpaceBefore {
+		head.WriteString("<")
+	}
+	if e.SpaceAfter {
+		head.WriteString(">")
+	}
+	var rest bytes.Buffer
+	for _, k := range e.attributeNames() {
+		rest.WriteString(" " + k + "=" + attributeValueSource(e.Attributes[k]))
+	}
+	for _, name := range e.SingleAttributes {
+		rest.WriteString(" [" + name + "]")
+	}
+	text := e.textValue()
+	if e.hasInlineChildren() {
+		text = e.inlineSource()
+	}
+	switch {
+	case text == "":
+	case e.explicitText(text):
+		rest.WriteString(" | " + text)
+	default:
+		rest.WriteString(" " + text)
+	}
+	return head.String(), rest.String()
+}
+
+// hasInlineChildren returns if the element has inline children or not.
+func (e *Element) hasInlineChildren() bool {
+	for _, child := range e.Children {
+		if child.Inline {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineSource returns the text which the element's inline children are
+// parsed from.
+func (e *Element) inlineSource() string {
+	var bf bytes.Buffer
+	for _, child := range e.Children {
+		if !child.Inline {
+			continue
+		}
+		switch child.Type {
+		case TypeLiteral:
+			bf.WriteString(strings.NewReplacer("#[", `\#[`, "#{", `\#{`).Replace(child.literalValue()))
+		case TypeOutputExpression:
+			bf.WriteString("#{" + strings.TrimSpace(strings.TrimPrefix(child.Text, "=")) + "}")
+		default:
+			line, _ := child.source()
+			bf.WriteString("#[" + line + "]")
+		}
+	}
+	return bf.String()
+}
+
+// explicitText returns if the text has to follow "|" so that it is not
+// parsed as attributes or not.
+func (e *Element) explicitText(text string) bool {
+	delimLeft, delimRight := e.delims()
+	var bf bytes.Buffer
+	for _, t := range lex("p "+text, 1, delimLeft, delimRight)[1:] {
+		if t.Type != TokenText && t.Type != TokenAction {
+			return true
+		}
+		bf.WriteString(t.Value)
+	}
+	return bf.String() != text
+}
+
+// attributeValueSource returns the attribute value's source. A value which
+// is empty, has whitespaces or starts with a quote is enclosed by double
+// quotes.
+func attributeValueSource(v string) string {
+	if v != "" && !strings.ContainsAny(v, " \t") && v[0] != '"' && v[0] != '\'' {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// writeSourceLine writes the line which is indented by the depth to the
+// buffer.
+func writeSourceLine(bf *bytes.Buffer, depth int, line string) {
+	bf.WriteString(strings.Repeat(printIndent, depth) + line + "\n")
+}
@@ -0,0 +1,191 @@
+package gold
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSprint(t *testing.T) {
+	stringTemplates := map[string]string{
+		"index": `doctype html
+html
+  head
+    script
+      var a = 1;
+        if (a) { b(); }
+  body#main.x data-a="a b" data-b='c "d"' title={{.T}} [hidden]
+    // comment
+    p Hello #[b bold #[i it]], #{.Name} and \#[text]
+    p | a=b
+    p.
+      raw line
+        deeper line
+    ul
+      li: a href=/x Link
+      li: span: b Deep
+    | literal #{.X}
+    ' trailing
+    p! <i>x</i>
+    span<> x
+    if .B
+      p B
+    else
+      = .C
+    case .K
+      when 1, 2
+        p one
+      default
+        p other
+    input type=text value=""
+    button onclick="alert(\"hi\")" Click
+    include footer
+`,
+		"footer": "footer",
+	}
+	expectedString := `doctype html
+html
+  head
+    script
+      var a = 1;
+        if (a) { b(); }
+  body#main.x data-a="a b" data-b="c \"d\"" title={{.T}} [hidden]
+    // comment
+    p Hello #[b bold #[i it]], #{.Name} and \#[text]
+    p | a=b
+    p.
+      raw line
+        deeper line
+    ul
+      li: a href=/x Link
+      li: span: b Deep
+    | literal #{.X}
+    ' trailing
+    p! <i>x</i>
+    span<> x
+    if .B
+      p B
+    else
+      = .C
+    case .K
+      when 1, 2
+        p one
+      default
+        p other
+    input type=text value=""
+    button onclick=alert("hi") Click
+    include footer
+`
+	g := NewGenerator(false)
+	tpl, err := g.parse("index", stringTemplates, false)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	src := Sprint(tpl)
+	if src != expectedString {
+		t.Errorf("Source should be %s. [actual: %s]", expectedString, src)
+	}
+	html, err := tpl.Html(stringTemplates, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+
+	// When the printed source is parsed.
+	stringTemplates["printed"] = src
+	printedTpl, err := g.parse("printed", stringTemplates, false)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if printedSrc := Sprint(printedTpl); printedSrc != src {
+		t.Errorf("Source should be %s. [actual: %s]", src, printedSrc)
+	}
+	printedHtml, err := printedTpl.Html(stringTemplates, nil)
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	if printedHtml != html {
+		t.Errorf("HTML should be %s. [actual: %s]", html, printedHtml)
+	}
+
+	// When the template extends a super template.
+	tpl, err = g.ParseTree("./test/TestGeneratorParseFile/012.gold")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString = `extends ./012_super
+block content
+  div#content.content style="font-size: 1rem; font-weight: bold;"
+    p AAA
+    p BBB
+`
+	if src := Sprint(tpl); src != expectedString {
+		t.Errorf("Source should be %s. [actual: %s]", expectedString, src)
+	}
+}
+
+func TestSprintParams(t *testing.T) {
+	g := NewGenerator(false)
+	src := "@param title\n@param href=\"#top link\"\n@param size:int?\n@param draft:bool=false\na href=%{href} %{title}\n"
+	tpl, err := g.ParseTreeString(map[string]string{"card": src}, "card")
+	if err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+		return
+	}
+	if printed := Sprint(tpl); printed != src {
+		t.Errorf("Source should be %s. [actual: %s]", src, printed)
+	}
+	tpl = NewTemplate("card", g).WithParams(&Param{Name: "title", Required: true}, &Param{Name: "size"})
+	expectedString := "@param title\n@param size?\n"
+	if printed := Sprint(tpl); printed != expectedString {
+		t.Errorf("Source should be %s. [actual: %s]", expectedString, printed)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	e := NewTag("p").WithText("Hello")
+	e.SetAttribute("title", "Greeting")
+	var bf bytes.Buffer
+	if err := Fprint(&bf, e); err != nil {
+		t.Errorf("An error(%s) occurred.", err.Error())
+	}
+	expectedString := "p title=Greeting Hello\n"
+	if bf.String() != expectedString {
+		t.Errorf("Buffer stirng should be %s. [actual: %s]", expectedString, bf.String())
+	}
+}
+
+func TestElementExplicitText(t *testing.T) {
+	e := NewTag("p")
+	cases := map[string]bool{
+		"Hello world":  false,
+		"{{.A}} b":     false,
+		"a=b":          true,
+		"[x]":          true,
+		"| x":          true,
+		" leading":     true,
+		"x a=b":        false,
+		"{{.A}}=b c=d": false,
+	}
+	for text, expected := range cases {
+		if actual := e.explicitText(text); actual != expected {
+			t.Errorf("explicitText(%q) should be %t. [actual: %t]", text, expected, actual)
+		}
+	}
+}
+
+func TestAttributeValueSource(t *testing.T) {
+	cases := map[string]string{
+		"a":         "a",
+		"":          `""`,
+		"a b":       `"a b"`,
+		`"a"`:       `"\"a\""`,
+		`'a'`:       `"'a'"`,
+		`a\b c`:     `"a\\b c"`,
+		"{{.A}}":    "{{.A}}",
+		"{{.A .B}}": `"{{.A .B}}"`,
+	}
+	for v, expected := range cases {
+		if actual := attributeValueSource(v); actual != expected {
+			t.Errorf("attributeValueSource(%q) should be %s. [actual: %s]", v, expected, actual)
+		}
+	}
+}
@@ -7,27 +7,59 @@ import (
 
 // A template represents a Gold template.
 type Template struct {
-	Path      string
-	Generator *Generator
-	Elements  []*Element
-	Super     *Template
-	Sub       *Template
-	Blocks    map[string]*Block
+	Path            string
+	Generator       *Generator
+	Elements        []*Element
+	Extends         string
+	Params          []*Param
+	Super           *Template
+	Blocks          map[string]*Block
+	stringTemplates map[string]string
+	// Sub is not used. The templates which extend a template are resolved
+	// while HTML is generated.
+	//
+	// Deprecated: Sub is always nil for parsed templates and is ignored.
+	Sub *Template
 }
 
 // AppendElement appends the element to the template's elements.
 func (t *Template) AppendElement(e *Element) {
-	t.Elements = append(t.Elements, e)
+	t.Elements = appendElement(t.Elements, e)
 }
 
 // Html generates an html and returns it.
 func (t *Template) Html(stringTemplates map[string]string, embedMap EmbedMap) (string, error) {
+	return t.html(newContext(stringTemplates), embedMap)
+}
+
+// html generates an html within the context and returns it.
+func (t *Template) html(ctx *context, embedMap EmbedMap) (string, error) {
 	if t.Super != nil {
-		return t.Super.Html(stringTemplates, embedMap)
+		ctx.subs = append(ctx.subs, t)
+		html, err := t.Super.html(ctx, embedMap)
+		ctx.subs = ctx.subs[:len(ctx.subs)-1]
+		if err != nil {
+			return "", err
+		}
+		var bf bytes.Buffer
+		for _, e := range t.Elements {
+			if e.Type != TypeDefine {
+				continue
+			}
+			if err := e.html(&bf, ctx); err != nil {
+				return "", err
+			}
+		}
+		return html + bf.String(), nil
 	} else {
+		start := len(ctx.includes)
+		if len(embedMap) > 0 {
+			ctx.scopes++
+			defer func() { ctx.scopes-- }()
+		}
 		var bf bytes.Buffer
 		for _, e := range t.Elements {
-			err := e.Html(&bf, stringTemplates)
+			err := e.html(&bf, ctx)
 			if err != nil {
 				return "", err
 			}
@@ -36,6 +68,10 @@ func (t *Template) Html(stringTemplates map[string]string, embedMap EmbedMap) (s
 		for key, value := range embedMap {
 			html = strings.Replace(html, "%{"+key+"}", value, -1)
 		}
+		for i := start; i < len(ctx.includes); i++ {
+			html = strings.Replace(html, includeMarker(i), ctx.includes[i], 1)
+		}
+		ctx.includes = ctx.includes[:start]
 		return html, nil
 	}
 }
@@ -57,6 +93,105 @@ func (t *Template) AddBlock(name string, block *Block) {
 	t.Blocks[name] = block
 }
 
+// blockElement returns the template's block element which has the name or
+// nil. The block elements in the template's blocks are also searched.
+func (t *Template) blockElement(name string) *Element {
+	var block *Element
+	find := func(node Node) bool {
+		if e, ok := node.(*Element); ok && block == nil && e.Type == TypeBlock && len(e.Tokens) > 1 && e.Tokens[1] == name {
+			block = e
+		}
+		return block == nil
+	}
+	for _, node := range t.Nodes() {
+		Inspect(node, find)
+	}
+	return block
+}
+
+// definesBlock returns if the template or its super templates define the
+// block which has the name as a block or a block element or not.
+func (t *Template) definesBlock(name string) bool {
+	for ; t != nil; t = t.Super {
+		if _, prs := t.Blocks[name]; prs || t.blockElement(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// definesTemplate returns if the template or its super templates have the
+// define element which has the name or not.
+func (t *Template) definesTemplate(name string) bool {
+	for ; t != nil; t = t.Super {
+		for _, e := range t.Elements {
+			if e.Type == TypeDefine && e.templateName() == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveTemplateCalls turns the template tags whose first words are the
+// names of the define elements of the template or its super templates into
+// template elements. The tags which have children are kept as they are.
+func (t *Template) resolveTemplateCalls() {
+	resolve := func(node Node) bool {
+		e, ok := node.(*Element)
+		if ok && e.Type == TypeTag && len(e.Tokens) > 1 && e.Tokens[0] == "template" && len(e.Children) == 0 && t.definesTemplate(e.Tokens[1]) {
+			e.Type, e.Tag, e.TextValues = TypeTemplate, "", nil
+		}
+		return true
+	}
+	for _, node := range t.Nodes() {
+		Inspect(node, resolve)
+	}
+}
+
+// templateCalls returns the template elements of the template, its super
+// templates and the templates it includes.
+func (t *Template) templateCalls(stringTemplates map[string]string) ([]*Element, error) {
+	var calls []*Element
+	var appendCalls func(elements []*Element) error
+	appendCalls = func(elements []*Element) error {
+		for _, e := range elements {
+			switch e.Type {
+			case TypeTemplate:
+				calls = append(calls, e)
+			case TypeInclude:
+				if len(e.Tokens) < 2 {
+					continue
+				}
+				incTpl, err := e.includedTemplate(stringTemplates)
+				if err != nil {
+					return err
+				}
+				incCalls, err := incTpl.templateCalls(stringTemplates)
+				if err != nil {
+					return err
+				}
+				calls = append(calls, incCalls...)
+			}
+			if err := appendCalls(e.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for tpl := t; tpl != nil; tpl = tpl.Super {
+		if err := appendCalls(tpl.Elements); err != nil {
+			return nil, err
+		}
+		for _, block := range tpl.Blocks {
+			if err := appendCalls(block.Elements); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return calls, nil
+}
+
 // NewTemplate generates a new template and returns it.
 func NewTemplate(path string, generator *Generator) *Template {
 	return &Template{Path: path, Generator: generator, Blocks: make(map[string]*Block)}
@@ -0,0 +1,69 @@
+package gold
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// xmlEscaper is the name of the function which escapes the outputs of the
+// actions in XML templates.
+const xmlEscaper = "xml"
+
+// xmlFuncs holds the functions which XML templates have.
+var xmlFuncs = map[string]interface{}{
+	xmlEscaper: xmlEscape,
+}
+
+// controlActions holds the keywords of the actions which do not output
+// values.
+var controlActions = map[string]bool{
+	"if": true, "else": true, "end": true, "range": true, "with": true,
+	"template": true, "define": true, "block": true, "break": true, "continue": true,
+}
+
+// xmlEscape returns the escaped XML text of the textual representation of
+// the arguments.
+func xmlEscape(args ...interface{}) string {
+	var bf bytes.Buffer
+	xml.EscapeText(&bf, []byte(fmt.Sprint(args...)))
+	return bf.String()
+}
+
+// escapeXMLActions pipes the outputs of the actions in the string to the
+// XML escaper.
+func escapeXMLActions(s, delimLeft, delimRight string) string {
+	if delimLeft == "" || delimRight == "" {
+		return s
+	}
+	var bf bytes.Buffer
+	for {
+		i := strings.Index(s, delimLeft)
+		if i < 0 {
+			break
+		}
+		j := strings.Index(s[i+len(delimLeft):], delimRight)
+		if j < 0 {
+			break
+		}
+		j += i + len(delimLeft)
+		bf.WriteString(s[:i] + delimLeft + xmlAction(s[i+len(delimLeft):j]) + delimRight)
+		s = s[j+len(delimRight):]
+	}
+	bf.WriteString(s)
+	return bf.String()
+}
+
+// xmlAction returns the action which pipes its output to the XML escaper.
+// Control actions, comments and variable declarations are returned as they
+// are.
+func xmlAction(action string) string {
+	pipeline := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(action, "-"), "-"))
+	fields := strings.Fields(pipeline)
+	if len(fields) == 0 || controlActions[fields[0]] || strings.HasPrefix(pipeline, "/*") || strings.Contains(pipeline, ":=") || (len(fields) > 1 && fields[1] == "=") {
+		return action
+	}
+	i := strings.LastIndex(action, pipeline)
+	return action[:i] + pipeline + " | " + xmlEscaper + action[i+len(pipeline):]
+}
@@ -0,0 +1,42 @@
+package gold
+
+import (
+	"testing"
+)
+
+func TestXMLEscape(t *testing.T) {
+	expectedString := "a&amp;b&lt;c&gt;&#34;d&#34;"
+	if s := xmlEscape(`a&b<c>"d"`); s != expectedString {
+		t.Errorf("Return string should be %s. [actual: %s]", expectedString, s)
+	}
+}
+
+func TestEscapeXMLActions(t *testing.T) {
+	expectedString := "a {{.B | xml}} c {{if .D}}d{{end}}"
+	if s := escapeXMLActions("a {{.B}} c {{if .D}}d{{end}}", "{{", "}}"); s != expectedString {
+		t.Errorf("Return string should be %s. [actual: %s]", expectedString, s)
+	}
+
+	// When the delimiters are empty.
+	if s := escapeXMLActions("{{.B}}", "", ""); s != "{{.B}}" {
+		t.Errorf("Return string should be %s. [actual: %s]", "{{.B}}", s)
+	}
+}
+
+func TestXMLAction(t *testing.T) {
+	cases := map[string]string{
+		".A":            ".A | xml",
+		"- .A -":        "- .A | xml -",
+		" .A ":          " .A | xml ",
+		"end":           "end",
+		"range .Items":  "range .Items",
+		"$a := .A":      "$a := .A",
+		"$a = .A":       "$a = .A",
+		"/* comment */": "/* comment */",
+	}
+	for action, expectedString := range cases {
+		if s := xmlAction(action); s != expectedString {
+			t.Errorf("Return string should be %s. [actual: %s]", expectedString, s)
+		}
+	}
+}